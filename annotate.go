@@ -0,0 +1,441 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package tlshacks
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Field is a range of bytes within a handshake message, such as a length prefix, a cipher
+// suite, or an extension.  Fields with Children are completely covered by their children.
+type Field struct {
+	Offset   int     `json:"offset"`
+	Length   int     `json:"length"`
+	Name     string  `json:"name"`
+	Value    string  `json:"value,omitempty"` // human-readable interpretation of the bytes
+	Children []Field `json:"children,omitempty"`
+}
+
+// annotator walks a handshake message, producing a Field for everything it reads.
+// Once a read fails, ok is false and subsequent reads do nothing.
+type annotator struct {
+	data   []byte
+	offset int // offset of data within the handshake message
+	fields []Field
+	ok     bool
+}
+
+func (a *annotator) empty() bool {
+	return len(a.data) == 0
+}
+
+// leaf reads n bytes as a field without children
+func (a *annotator) leaf(n int, name string, value string) []byte {
+	if !a.ok || len(a.data) < n {
+		a.ok = false
+		return nil
+	}
+	bytes := a.data[:n]
+	a.fields = append(a.fields, Field{Offset: a.offset, Length: n, Name: name, Value: value})
+	a.data = a.data[n:]
+	a.offset += n
+	return bytes
+}
+
+// number reads an n byte big-endian integer, described by value (or in decimal, if value is nil)
+func (a *annotator) number(n int, name string, value func(uint64) string) uint64 {
+	if !a.ok || len(a.data) < n {
+		a.ok = false
+		return 0
+	}
+	var v uint64
+	for _, b := range a.data[:n] {
+		v = v<<8 | uint64(b)
+	}
+	if value == nil {
+		value = func(v uint64) string { return strconv.FormatUint(v, 10) }
+	}
+	a.leaf(n, name, value(v))
+	return v
+}
+
+// opaque reads the remaining bytes, if any, as a single field
+func (a *annotator) opaque(name string, value func([]byte) string) {
+	if !a.empty() {
+		v := ""
+		if value != nil {
+			v = value(a.data)
+		}
+		a.leaf(len(a.data), name, v)
+	}
+}
+
+// group combines the fields read by body into a single field with children
+func (a *annotator) group(name string, body func(*annotator)) {
+	start, offset := len(a.fields), a.offset
+	body(a)
+	if !a.ok {
+		return
+	}
+	children := append([]Field(nil), a.fields[start:]...)
+	a.fields = append(a.fields[:start], Field{Offset: offset, Length: a.offset - offset, Name: name, Children: children})
+}
+
+// vector reads a length prefix of lengthSize bytes followed by that many bytes, which are
+// annotated by body.  If body fails to consume the bytes exactly, they are annotated as a
+// single unparsed field instead.
+func (a *annotator) vector(lengthSize int, name string, body func(*annotator)) {
+	a.group(name, func(a *annotator) {
+		length := int(a.number(lengthSize, "length", nil))
+		if !a.ok || len(a.data) < length {
+			a.ok = false
+			return
+		}
+		sub := &annotator{data: a.data[:length], offset: a.offset, ok: true}
+		if body != nil {
+			body(sub)
+		}
+		if !sub.ok || !sub.empty() {
+			sub = &annotator{data: a.data[:length], offset: a.offset, ok: true}
+			sub.opaque("unparsed", nil)
+		}
+		a.fields = append(a.fields, sub.fields...)
+		a.data = a.data[length:]
+		a.offset += length
+	})
+}
+
+// each calls read until the data is exhausted or a read fails
+func (a *annotator) each(read func(*annotator)) {
+	for a.ok && !a.empty() {
+		read(a)
+	}
+}
+
+func named(names map[uint16]string) func(uint64) string {
+	return func(v uint64) string {
+		if isGreaseValue(uint16(v)) {
+			return fmt.Sprintf("0x%04x GREASE", v)
+		} else if name := names[uint16(v)]; name != "" {
+			return fmt.Sprintf("0x%04x %s", v, name)
+		}
+		return fmt.Sprintf("0x%04x", v)
+	}
+}
+
+func versionValue(v uint64) string {
+	return ProtocolVersion(v).String()
+}
+
+func cipherSuiteValue(v uint64) string {
+	return named(map[uint16]string{uint16(v): CipherSuites[uint16(v)].Name})(v)
+}
+
+func stringValue(b []byte) string {
+	return strconv.Quote(string(b))
+}
+
+func list(lengthSize int, name string, elemSize int, elemName string, value func(uint64) string) func(*annotator) {
+	return func(a *annotator) {
+		a.vector(lengthSize, name, func(a *annotator) {
+			a.each(func(a *annotator) { a.number(elemSize, elemName, value) })
+		})
+	}
+}
+
+func protocolNameList(a *annotator) {
+	a.vector(2, "protocol_name_list", func(a *annotator) {
+		a.each(func(a *annotator) {
+			a.vector(1, "protocol_name", func(a *annotator) { a.opaque("name", stringValue) })
+		})
+	})
+}
+
+var signatureAlgorithmList = list(2, "supported_signature_algorithms", 2, "signature_scheme", named(SignatureAlgorithmNames))
+
+var certificateTypeList = list(1, "certificate_types", 1, "certificate_type", func(v uint64) string {
+	if name := CertificateTypes[uint8(v)]; name != "" {
+		return fmt.Sprintf("%d %s", v, name)
+	}
+	return strconv.FormatUint(v, 10)
+})
+
+func noData(a *annotator) {}
+
+func distinguishedNameValue(b []byte) string {
+	name, _ := makeDistinguishedName(b)
+	return name.String
+}
+
+func distinguishedName(a *annotator) {
+	a.vector(2, "distinguished_name", func(a *annotator) { a.opaque("name", distinguishedNameValue) })
+}
+
+func ocspStatusRequest(a *annotator) {
+	a.vector(2, "responder_id_list", func(a *annotator) {
+		a.each(func(a *annotator) {
+			a.vector(2, "responder_id", func(a *annotator) { a.opaque("responder_id", nil) })
+		})
+	})
+	a.vector(2, "request_extensions", func(a *annotator) { a.opaque("extensions", nil) })
+}
+
+// extensionAnnotators annotate the contents of extension_data.  There is one for every
+// built-in extension parser, following the same format.
+var extensionAnnotators = map[uint16]func(*annotator){
+	0: func(a *annotator) {
+		a.vector(2, "server_name_list", func(a *annotator) {
+			a.each(func(a *annotator) {
+				a.group("server_name", func(a *annotator) {
+					a.number(1, "name_type", nil)
+					a.vector(2, "host_name", func(a *annotator) { a.opaque("name", stringValue) })
+				})
+			})
+		})
+	},
+	1: func(a *annotator) {
+		a.number(1, "max_fragment_length", nil)
+	},
+	3: func(a *annotator) {
+		a.vector(2, "trusted_authorities_list", func(a *annotator) {
+			a.each(func(a *annotator) {
+				a.group("trusted_authority", func(a *annotator) {
+					switch a.number(1, "identifier_type", nil) {
+					case 0:
+					case 1, 3:
+						a.leaf(20, "sha1_hash", "")
+					case 2:
+						distinguishedName(a)
+					default:
+						a.ok = false
+					}
+				})
+			})
+		})
+	},
+	5: func(a *annotator) {
+		if a.number(1, "status_type", nil) == 1 {
+			ocspStatusRequest(a)
+		} else {
+			a.opaque("request", nil)
+		}
+	},
+	10: list(2, "named_group_list", 2, "named_group", named(GroupNames)),
+	11: list(1, "ec_point_format_list", 1, "ec_point_format", nil),
+	13: signatureAlgorithmList,
+	14: func(a *annotator) {
+		list(2, "srtp_protection_profiles", 2, "srtp_protection_profile", named(SRTPProtectionProfiles))(a)
+		a.vector(1, "srtp_mki", func(a *annotator) { a.opaque("mki", nil) })
+	},
+	15: func(a *annotator) { a.number(1, "mode", nil) },
+	16: protocolNameList,
+	17: func(a *annotator) {
+		a.vector(2, "certificate_status_req_list", func(a *annotator) {
+			a.each(func(a *annotator) {
+				a.group("certificate_status_request_item", func(a *annotator) {
+					statusType := a.number(1, "status_type", nil)
+					a.vector(2, "request", func(a *annotator) {
+						if statusType == 1 || statusType == 2 {
+							ocspStatusRequest(a)
+						} else {
+							a.opaque("request", nil)
+						}
+					})
+				})
+			})
+		})
+	},
+	18: noData,
+	19: certificateTypeList,
+	20: certificateTypeList,
+	21: func(a *annotator) { a.opaque("padding", nil) },
+	22: noData,
+	23: noData,
+	24: func(a *annotator) {
+		a.number(1, "major_version", nil)
+		a.number(1, "minor_version", nil)
+		list(1, "key_parameters_list", 1, "key_parameter", nil)(a)
+	},
+	27: list(1, "algorithms", 2, "algorithm", named(CertificateCompressionAlgorithms)),
+	28: func(a *annotator) { a.number(2, "record_size_limit", nil) },
+	34: signatureAlgorithmList,
+	35: func(a *annotator) { a.opaque("ticket", nil) },
+	41: func(a *annotator) {
+		a.vector(2, "identities", func(a *annotator) {
+			a.each(func(a *annotator) {
+				a.group("psk_identity", func(a *annotator) {
+					a.vector(2, "identity", func(a *annotator) { a.opaque("identity", nil) })
+					a.number(4, "obfuscated_ticket_age", nil)
+				})
+			})
+		})
+		a.vector(2, "binders", func(a *annotator) {
+			a.each(func(a *annotator) {
+				a.vector(1, "binder", func(a *annotator) { a.opaque("binder", nil) })
+			})
+		})
+	},
+	42: noData,
+	43: list(1, "versions", 2, "version", versionValue),
+	45: list(1, "ke_modes", 1, "ke_mode", nil),
+	47: func(a *annotator) {
+		a.vector(2, "authorities", func(a *annotator) { a.each(distinguishedName) })
+	},
+	49: noData,
+	50: signatureAlgorithmList,
+	51: func(a *annotator) {
+		a.vector(2, "client_shares", func(a *annotator) {
+			a.each(func(a *annotator) {
+				a.group("key_share_entry", func(a *annotator) {
+					a.number(2, "group", named(GroupNames))
+					a.vector(2, "key_exchange", func(a *annotator) { a.opaque("key_exchange", nil) })
+				})
+			})
+		})
+	},
+	17513: protocolNameList,
+	17613: protocolNameList,
+	0xff01: func(a *annotator) {
+		a.vector(1, "renegotiated_connection", func(a *annotator) { a.opaque("verify_data", nil) })
+	},
+}
+
+func (a *annotator) extension() {
+	if len(a.data) < 2 {
+		a.ok = false
+		return
+	}
+	extType := uint16(a.data[0])<<8 | uint16(a.data[1])
+	name := ExtensionName(extType)
+	if name == "" {
+		name = "unknown"
+	}
+	if isGreaseValue(extType) {
+		name = "GREASE"
+	}
+	a.group("extension: "+name, func(a *annotator) {
+		a.number(2, "extension_type", func(v uint64) string { return fmt.Sprintf("0x%04x %s", v, name) })
+		body := extensionAnnotators[extType]
+		if body == nil {
+			body = func(a *annotator) { a.opaque("data", nil) }
+		}
+		a.vector(2, "extension_data", body)
+	})
+}
+
+// AnnotateClientHello returns fields describing every byte of the ClientHello's Raw bytes,
+// in order.  Extensions are annotated according to their standard format, so extensions
+// with custom parsers are annotated as opaque data.
+func AnnotateClientHello(hello *ClientHelloInfo) []Field {
+	a := &annotator{data: hello.Raw, ok: true}
+	a.number(1, "msg_type", func(v uint64) string { return HandshakeTypes[uint8(v)] })
+	a.vector(3, "client_hello", func(a *annotator) {
+		a.number(2, "legacy_version", versionValue)
+		a.leaf(32, "random", "")
+		a.vector(1, "legacy_session_id", func(a *annotator) { a.opaque("session_id", nil) })
+		a.vector(2, "cipher_suites", func(a *annotator) {
+			a.each(func(a *annotator) { a.number(2, "cipher_suite", cipherSuiteValue) })
+		})
+		a.vector(1, "compression_methods", func(a *annotator) {
+			a.each(func(a *annotator) { a.number(1, "compression_method", nil) })
+		})
+		if !a.empty() {
+			a.vector(2, "extensions", func(a *annotator) { a.each((*annotator).extension) })
+		}
+	})
+	if !a.ok {
+		// Can't happen for a ClientHello which was successfully unmarshaled
+		a.ok = true
+		a.opaque("unparsed", nil)
+	}
+	return a.fields
+}
+
+// HexDumpLine is one line of an annotated hex dump.  Fields with children get a line
+// with no bytes, and fields longer than 16 bytes are split across multiple lines, with
+// the annotation only on the first.
+type HexDumpLine struct {
+	Offset     int    `json:"offset"`
+	Bytes      []byte `json:"bytes"`
+	Depth      int    `json:"depth"`
+	Annotation string `json:"annotation"`
+}
+
+const hexDumpWidth = 16
+
+func byteCount(n int) string {
+	if n == 1 {
+		return "1 byte"
+	}
+	return fmt.Sprintf("%d bytes", n)
+}
+
+// HexDumpLines lays out fields, as returned by AnnotateClientHello, as lines of a hex dump of raw
+func HexDumpLines(raw []byte, fields []Field) []HexDumpLine {
+	var lines []HexDumpLine
+	var walk func(fields []Field, depth int)
+	walk = func(fields []Field, depth int) {
+		for _, field := range fields {
+			if field.Children != nil {
+				lines = append(lines, HexDumpLine{Offset: field.Offset, Depth: depth, Annotation: fmt.Sprintf("%s (%s)", field.Name, byteCount(field.Length))})
+				walk(field.Children, depth+1)
+				continue
+			}
+			annotation := field.Name
+			if field.Value != "" {
+				annotation += ": " + field.Value
+			}
+			for i := 0; i < field.Length; i += hexDumpWidth {
+				line := HexDumpLine{Offset: field.Offset + i, Depth: depth, Bytes: raw[field.Offset+i : field.Offset+min(i+hexDumpWidth, field.Length)]}
+				if i == 0 {
+					line.Annotation = annotation
+				}
+				lines = append(lines, line)
+			}
+		}
+	}
+	walk(fields, 0)
+	return lines
+}
+
+// WriteHexDump writes an annotated hex dump of raw to w, using fields as returned by AnnotateClientHello
+func WriteHexDump(w io.Writer, raw []byte, fields []Field) error {
+	for _, line := range HexDumpLines(raw, fields) {
+		hexBytes := make([]string, len(line.Bytes))
+		for i, b := range line.Bytes {
+			hexBytes[i] = hex.EncodeToString([]byte{b})
+		}
+		text := fmt.Sprintf("%04x  %-*s  %s%s", line.Offset, hexDumpWidth*3-1, strings.Join(hexBytes, " "), strings.Repeat("  ", line.Depth), line.Annotation)
+		if _, err := fmt.Fprintln(w, strings.TrimRight(text, " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
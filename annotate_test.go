@@ -0,0 +1,183 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package tlshacks
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"strings"
+	"testing"
+)
+
+func testDistinguishedName(t *testing.T) []byte {
+	der, err := asn1.Marshal(pkix.Name{CommonName: "Example CA", Organization: []string{"Example"}}.ToRDNSequence())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+func lengthPrefixed(size int, data []byte) []byte {
+	prefix := make([]byte, size)
+	for i := range prefix {
+		prefix[i] = byte(len(data) >> (8 * (size - 1 - i)))
+	}
+	return append(prefix, data...)
+}
+
+func concat(parts ...[]byte) []byte {
+	var result []byte
+	for _, part := range parts {
+		result = append(result, part...)
+	}
+	return result
+}
+
+// checkCoverage checks that fields cover length bytes starting at start, in order and without gaps
+func checkCoverage(t *testing.T, context string, fields []Field, start int, length int) {
+	t.Helper()
+	offset := start
+	for _, field := range fields {
+		if field.Offset != offset {
+			t.Errorf("%s: field %q starts at %d, expected %d", context, field.Name, field.Offset, offset)
+			return
+		}
+		if field.Name == "unparsed" {
+			t.Errorf("%s: %d bytes at offset %d are unparsed", context, field.Length, field.Offset)
+		}
+		if field.Children != nil {
+			checkCoverage(t, context+"/"+field.Name, field.Children, field.Offset, field.Length)
+		}
+		offset += field.Length
+	}
+	if offset != start+length {
+		t.Errorf("%s: fields end at %d, expected %d", context, offset, start+length)
+	}
+}
+
+func TestExtensionAnnotatorsCoverParsers(t *testing.T) {
+	for extType := range extensionParsers {
+		if extensionAnnotators[extType] == nil {
+			t.Errorf("extension %d (%s) has a parser but no annotator", extType, ExtensionName(extType))
+		}
+	}
+	for extType := range extensionAnnotators {
+		if extensionParsers[extType] == nil {
+			t.Errorf("extension %d (%s) has an annotator but no parser", extType, ExtensionName(extType))
+		}
+	}
+}
+
+func TestExtensionAnnotatorsMatchParsers(t *testing.T) {
+	dn := testDistinguishedName(t)
+	sha1Hash := []byte(strings.Repeat("\x42", 20))
+	binder := []byte(strings.Repeat("\x11", 32))
+	tests := []struct {
+		extType uint16
+		data    []byte
+	}{
+		{0, ServerNameExtension("example.com").Data},
+		{1, []byte{2}},
+		{3, lengthPrefixed(2, concat([]byte{0}, []byte{1}, sha1Hash, []byte{2}, lengthPrefixed(2, dn), []byte{3}, sha1Hash))},
+		{5, []byte{1, 0, 0, 0, 0}},
+		{5, []byte{7, 1, 2, 3}},
+		{10, SupportedGroupsExtension(29, 23).Data},
+		{11, ECPointFormatsExtension(0).Data},
+		{13, SignatureAlgorithmsExtension(0x0403, 0x0804).Data},
+		{14, []byte{0, 4, 0, 1, 0, 7, 1, 0x99}},
+		{15, []byte{1}},
+		{16, ALPNExtension("h2", "http/1.1").Data},
+		{17, lengthPrefixed(2, concat([]byte{2}, lengthPrefixed(2, []byte{0, 0, 0, 0}), []byte{9}, lengthPrefixed(2, []byte{1, 2})))},
+		{18, []byte{}},
+		{19, []byte{2, 0, 2}},
+		{20, []byte{1, 0}},
+		{21, []byte{0, 0, 0}},
+		{22, []byte{}},
+		{23, []byte{}},
+		{24, []byte{0, 13, 2, 0, 2}},
+		{27, []byte{4, 0, 2, 0, 1}},
+		{28, []byte{0x40, 0x01}},
+		{34, SignatureAlgorithmsExtension(0x0403).Data},
+		{35, []byte{1, 2, 3}},
+		{41, concat(lengthPrefixed(2, concat(lengthPrefixed(2, []byte{0xaa, 0xbb}), []byte{0, 0, 0, 1})), lengthPrefixed(2, lengthPrefixed(1, binder)))},
+		{42, []byte{}},
+		{43, SupportedVersionsExtension(0x0304, 0x0303).Data},
+		{45, []byte{1, 1}},
+		{47, lengthPrefixed(2, lengthPrefixed(2, dn))},
+		{49, []byte{}},
+		{50, SignatureAlgorithmsExtension(0x0401).Data},
+		{51, KeyShareExtension(KeyShareEntry{Group: 29}).Data},
+		{17513, ALPNExtension("h2").Data},
+		{17613, ALPNExtension("h2").Data},
+		{0xff01, []byte{0}},
+	}
+	for _, test := range tests {
+		name := ExtensionName(test.extType)
+		if !extensionValid(extensionParsers[test.extType](test.data)) {
+			t.Errorf("extension %d (%s): test data % x does not parse", test.extType, name, test.data)
+			continue
+		}
+		a := &annotator{data: test.data, ok: true}
+		extensionAnnotators[test.extType](a)
+		if !a.ok || !a.empty() {
+			t.Errorf("extension %d (%s): annotator did not consume % x", test.extType, name, test.data)
+			continue
+		}
+		checkCoverage(t, name, a.fields, 0, len(test.data))
+	}
+}
+
+func TestAnnotateClientHello(t *testing.T) {
+	builder := ClientHelloBuilder{
+		SessionID:    []byte{1, 2, 3, 4},
+		CipherSuites: []uint16{0x1301, 0xc02f},
+		Extensions: []BuilderExtension{
+			ServerNameExtension("example.com"),
+			SupportedGroupsExtension(29, 23),
+			SignatureAlgorithmsExtension(0x0403, 0x0804),
+			ALPNExtension("h2"),
+			KeyShareExtension(KeyShareEntry{Group: 29}),
+			{Type: 0x7a7a, Data: []byte{0xde, 0xad}},
+		},
+		Grease: true,
+		PadTo:  512,
+	}
+	hello, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := AnnotateClientHello(hello)
+	checkCoverage(t, "ClientHello", fields, 0, len(hello.Raw))
+
+	lines := HexDumpLines(hello.Raw, fields)
+	var dumped int
+	for _, line := range lines {
+		dumped += len(line.Bytes)
+	}
+	if dumped != len(hello.Raw) {
+		t.Errorf("hex dump contains %d bytes, expected %d", dumped, len(hello.Raw))
+	}
+}
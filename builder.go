@@ -0,0 +1,399 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package tlshacks
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	mathrand "math/rand/v2"
+
+	"golang.org/x/crypto/cryptobyte"
+)
+
+// BuilderExtension is an extension to be included in a ClientHello built by ClientHelloBuilder.
+// Use one of the *Extension functions to construct extensions with typed data, or construct
+// it directly to include arbitrary data.  If a constructor can't encode its arguments (for
+// example, a list longer than its length prefix allows), Marshal returns an error.
+type BuilderExtension struct {
+	Type uint16
+	Data []byte
+
+	err error // set if the constructor couldn't encode the data; returned by Marshal
+}
+
+func buildExtension(extType uint16, build func(*cryptobyte.Builder)) BuilderExtension {
+	b := cryptobyte.NewBuilder(nil)
+	build(b)
+	data, err := b.Bytes()
+	if err != nil {
+		return BuilderExtension{Type: extType, err: fmt.Errorf("unable to encode extension %d (%s): %w", extType, ExtensionName(extType), err)}
+	}
+	return BuilderExtension{Type: extType, Data: data}
+}
+
+func addUint16List(b *cryptobyte.Builder, list []uint16) {
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+		for _, v := range list {
+			b.AddUint16(v)
+		}
+	})
+}
+
+func EmptyExtension(extType uint16) BuilderExtension {
+	return BuilderExtension{Type: extType, Data: []byte{}}
+}
+
+func ServerNameExtension(hostname string) BuilderExtension {
+	return buildExtension(0, func(b *cryptobyte.Builder) {
+		b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+			b.AddUint8(0) // host_name
+			b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+				b.AddBytes([]byte(hostname))
+			})
+		})
+	})
+}
+
+func SupportedGroupsExtension(groups ...uint16) BuilderExtension {
+	return buildExtension(10, func(b *cryptobyte.Builder) { addUint16List(b, groups) })
+}
+
+func ECPointFormatsExtension(formats ...uint8) BuilderExtension {
+	return buildExtension(11, func(b *cryptobyte.Builder) {
+		b.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes(formats) })
+	})
+}
+
+func SignatureAlgorithmsExtension(algorithms ...uint16) BuilderExtension {
+	return buildExtension(13, func(b *cryptobyte.Builder) { addUint16List(b, algorithms) })
+}
+
+func ALPNExtension(protocols ...string) BuilderExtension {
+	return buildExtension(16, func(b *cryptobyte.Builder) {
+		b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+			for _, protocol := range protocols {
+				b.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes([]byte(protocol)) })
+			}
+		})
+	})
+}
+
+func SupportedVersionsExtension(versions ...ProtocolVersion) BuilderExtension {
+	return buildExtension(43, func(b *cryptobyte.Builder) {
+		b.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) {
+			for _, version := range versions {
+				b.AddUint16(uint16(version))
+			}
+		})
+	})
+}
+
+func PSKKeyExchangeModesExtension(modes ...uint8) BuilderExtension {
+	return buildExtension(45, func(b *cryptobyte.Builder) {
+		b.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes(modes) })
+	})
+}
+
+// RenegotiationInfoExtension returns the empty renegotiation_info extension sent in an initial handshake
+func RenegotiationInfoExtension() BuilderExtension {
+	return BuilderExtension{Type: 0xff01, Data: []byte{0}}
+}
+
+// KeyShareExtension returns a key_share extension containing the given shares.  Shares with
+// an empty KeyExchange are filled with random bytes of the length expected for the group.
+func KeyShareExtension(shares ...KeyShareEntry) BuilderExtension {
+	return buildExtension(51, func(b *cryptobyte.Builder) {
+		b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+			for _, share := range shares {
+				b.AddUint16(share.Group)
+				b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+					if len(share.KeyExchange) > 0 {
+						b.AddBytes(share.KeyExchange)
+					} else {
+						b.AddBytes(randomBytes(keyShareLength(share.Group)))
+					}
+				})
+			}
+		})
+	})
+}
+
+// keyShareLength returns the length of a key share for the given group
+func keyShareLength(group uint16) int {
+	switch group {
+	case 23: // secp256r1
+		return 65
+	case 24: // secp384r1
+		return 97
+	case 25: // secp521r1
+		return 133
+	case 29: // x25519
+		return 32
+	case 30: // x448
+		return 56
+	case 0x11ec: // X25519MLKEM768
+		return 1184 + 32
+	case 0x11eb: // SecP256r1MLKEM768
+		return 65 + 1184
+	default:
+		return 32
+	}
+}
+
+func randomBytes(n int) []byte {
+	bytes := make([]byte, n)
+	if _, err := rand.Read(bytes); err != nil {
+		panic(err)
+	}
+	return bytes
+}
+
+// ClientHelloBuilder assembles synthetic ClientHellos.  The zero value builds a ClientHello
+// for TLS 1.2 and 1.3 with no cipher suites or extensions.
+type ClientHelloBuilder struct {
+	// MinVersion and MaxVersion specify the range of versions to offer, defaulting to TLS 1.2
+	// and TLS 1.3.  If MaxVersion is TLS 1.3 or higher, legacy_version is set to TLS 1.2 and,
+	// unless Extensions already contains one, a supported_versions extension is added.
+	// DTLS versions are not supported.
+	MinVersion ProtocolVersion
+	MaxVersion ProtocolVersion
+
+	Random             []byte // if nil, 32 random bytes are used
+	SessionID          []byte
+	CipherSuites       []uint16
+	CompressionMethods []uint8 // if nil, only the null compression method is offered
+	Extensions         []BuilderExtension
+
+	// Grease inserts GREASE values (RFC 8701) in the same positions as Chrome: at the
+	// start of the cipher suites, supported_groups, key_share, and supported_versions,
+	// and as the first and last extensions.
+	Grease bool
+
+	// If PadTo is non-zero, a padding extension is added if necessary to make the
+	// handshake message at least PadTo bytes long.
+	PadTo int
+}
+
+func randomGreaseValues(n int) []uint16 {
+	values := make([]uint16, 0, n)
+	for _, i := range mathrand.Perm(16)[:n] {
+		values = append(values, uint16(i)<<12|0x0a00|uint16(i)<<4|0x0a)
+	}
+	return values
+}
+
+// greaseExtension prepends a GREASE value to the list in the given extension
+func greaseExtension(ext BuilderExtension, grease uint16) BuilderExtension {
+	switch ext.Type {
+	case 10:
+		if data := ParseSupportedGroupsData(ext.Data).(*SupportedGroupsData); data.Valid {
+			return SupportedGroupsExtension(append([]uint16{grease}, data.Groups...)...)
+		}
+	case 43:
+		if data := ParseSupportedVersionsData(ext.Data).(*SupportedVersionsData); data.Valid {
+			return SupportedVersionsExtension(append([]ProtocolVersion{ProtocolVersion(grease)}, data.Versions...)...)
+		}
+	case 51:
+		if data := ParseKeyShareData(ext.Data).(*KeyShareData); data.Valid {
+			return KeyShareExtension(append([]KeyShareEntry{{Group: grease, KeyExchange: []byte{0}}}, data.Shares...)...)
+		}
+	}
+	return ext
+}
+
+func (builder *ClientHelloBuilder) versions() (ProtocolVersion, ProtocolVersion, error) {
+	minVersion, maxVersion := builder.MinVersion, builder.MaxVersion
+	if minVersion == 0 {
+		minVersion = 0x0303
+	}
+	if maxVersion == 0 {
+		maxVersion = 0x0304
+	}
+	// DTLS versions count downwards from 0xfeff, so only SSL 3.0 and TLS versions are supported
+	if minVersion>>8 != 0x03 {
+		return 0, 0, fmt.Errorf("MinVersion (%s) is not an SSL or TLS version", minVersion)
+	}
+	if maxVersion>>8 != 0x03 {
+		return 0, 0, fmt.Errorf("MaxVersion (%s) is not an SSL or TLS version", maxVersion)
+	}
+	if minVersion > maxVersion {
+		return 0, 0, fmt.Errorf("MinVersion (%s) is greater than MaxVersion (%s)", minVersion, maxVersion)
+	}
+	return minVersion, maxVersion, nil
+}
+
+func (builder *ClientHelloBuilder) extensions(greaseValues []uint16) ([]BuilderExtension, error) {
+	minVersion, maxVersion, err := builder.versions()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ext := range builder.Extensions {
+		if ext.err != nil {
+			return nil, ext.err
+		}
+	}
+
+	extensions := make([]BuilderExtension, 0, len(builder.Extensions)+4)
+	if builder.Grease {
+		extensions = append(extensions, EmptyExtension(greaseValues[1]))
+	}
+	hasSupportedVersions := false
+	for _, ext := range builder.Extensions {
+		if ext.Type == 43 {
+			hasSupportedVersions = true
+		}
+		if builder.Grease {
+			ext = greaseExtension(ext, greaseValues[0])
+		}
+		extensions = append(extensions, ext)
+	}
+	if !hasSupportedVersions && maxVersion >= 0x0304 {
+		var versions []ProtocolVersion
+		if builder.Grease {
+			versions = append(versions, ProtocolVersion(greaseValues[0]))
+		}
+		for v := maxVersion; v >= minVersion; v-- {
+			versions = append(versions, v)
+		}
+		extensions = append(extensions, SupportedVersionsExtension(versions...))
+	}
+
+	// pre_shared_key must remain the last extension
+	var preSharedKey *BuilderExtension
+	if len(extensions) > 0 && extensions[len(extensions)-1].Type == 41 {
+		preSharedKey = &extensions[len(extensions)-1]
+		extensions = extensions[:len(extensions)-1]
+	}
+	if builder.Grease {
+		extensions = append(extensions, BuilderExtension{Type: greaseValues[2], Data: []byte{0}})
+	}
+	if preSharedKey != nil {
+		extensions = append(extensions, *preSharedKey)
+	}
+	return extensions, nil
+}
+
+func (builder *ClientHelloBuilder) marshal(legacyVersion ProtocolVersion, random []byte, cipherSuites []uint16, extensions []BuilderExtension) ([]byte, error) {
+	compressionMethods := builder.CompressionMethods
+	if compressionMethods == nil {
+		compressionMethods = []uint8{0}
+	}
+
+	b := cryptobyte.NewBuilder(nil)
+	b.AddUint8(1) // client_hello
+	b.AddUint24LengthPrefixed(func(b *cryptobyte.Builder) {
+		b.AddUint16(uint16(legacyVersion))
+		b.AddBytes(random)
+		b.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes(builder.SessionID) })
+		addUint16List(b, cipherSuites)
+		b.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes(compressionMethods) })
+		if len(extensions) > 0 {
+			b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+				for _, ext := range extensions {
+					b.AddUint16(ext.Type)
+					b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes(ext.Data) })
+				}
+			})
+		}
+	})
+	return b.Bytes()
+}
+
+// Marshal returns the ClientHello as a handshake message
+func (builder *ClientHelloBuilder) Marshal() ([]byte, error) {
+	_, maxVersion, err := builder.versions()
+	if err != nil {
+		return nil, err
+	}
+	legacyVersion := min(maxVersion, 0x0303)
+
+	random := builder.Random
+	if random == nil {
+		random = randomBytes(32)
+	} else if len(random) != 32 {
+		return nil, errors.New("Random is not 32 bytes long")
+	}
+	if len(builder.SessionID) > 32 {
+		return nil, errors.New("SessionID is longer than 32 bytes")
+	}
+
+	// greaseValues[0] is used for lists, and [1] and [2] for the first and last extensions
+	greaseValues := randomGreaseValues(3)
+
+	cipherSuites := builder.CipherSuites
+	if builder.Grease {
+		cipherSuites = append([]uint16{greaseValues[0]}, cipherSuites...)
+	}
+
+	extensions, err := builder.extensions(greaseValues)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := builder.marshal(legacyVersion, random, cipherSuites, extensions)
+	if err != nil {
+		return nil, err
+	}
+	if len(message) >= builder.PadTo {
+		return message, nil
+	}
+
+	paddingLength := max(0, builder.PadTo-len(message)-4)
+	if len(extensions) == 0 {
+		paddingLength = max(0, paddingLength-2) // account for the extensions length field
+	}
+	padding := BuilderExtension{Type: 21, Data: make([]byte, paddingLength)}
+	if len(extensions) > 0 && extensions[len(extensions)-1].Type == 41 {
+		extensions = append(extensions[:len(extensions)-1], padding, extensions[len(extensions)-1])
+	} else {
+		extensions = append(extensions, padding)
+	}
+	return builder.marshal(legacyVersion, random, cipherSuites, extensions)
+}
+
+// MarshalRecords returns the ClientHello as one or more TLS handshake records
+func (builder *ClientHelloBuilder) MarshalRecords() ([]byte, error) {
+	message, err := builder.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return MakeHandshakeRecords(message), nil
+}
+
+// Build returns the parsed form of the ClientHello
+func (builder *ClientHelloBuilder) Build() (*ClientHelloInfo, error) {
+	message, err := builder.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	info := UnmarshalClientHello(message)
+	if info == nil {
+		return nil, errors.New("built ClientHello does not parse")
+	}
+	return info, nil
+}
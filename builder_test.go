@@ -0,0 +1,213 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package tlshacks
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBuilderRoundTrip(t *testing.T) {
+	random := bytes.Repeat([]byte{0x42}, 32)
+	sessionID := bytes.Repeat([]byte{0x17}, 32)
+	builder := &ClientHelloBuilder{
+		Random:       random,
+		SessionID:    sessionID,
+		CipherSuites: []uint16{0x1301, 0x1302, 0xc02b},
+		Extensions: []BuilderExtension{
+			ServerNameExtension("example.com"),
+			SupportedGroupsExtension(29, 23),
+			ECPointFormatsExtension(0),
+			SignatureAlgorithmsExtension(0x0403, 0x0804),
+			ALPNExtension("h2", "http/1.1"),
+			PSKKeyExchangeModesExtension(1),
+			KeyShareExtension(KeyShareEntry{Group: 29}),
+			RenegotiationInfoExtension(),
+		},
+	}
+	message, err := builder.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := UnmarshalClientHello(message)
+	if info == nil {
+		t.Fatal("built ClientHello does not parse")
+	}
+
+	if info.Version != 0x0303 {
+		t.Errorf("legacy_version is %s, expected TLS 1.2", info.Version)
+	}
+	if !bytes.Equal(info.Random, random) {
+		t.Errorf("random is %x, expected %x", info.Random, random)
+	}
+	if !bytes.Equal(info.SessionID, sessionID) {
+		t.Errorf("session ID is %x, expected %x", info.SessionID, sessionID)
+	}
+	if codes := cipherSuitesOf(info); !slices.Equal(codes, builder.CipherSuites) {
+		t.Errorf("cipher suites are %v, expected %v", codes, builder.CipherSuites)
+	}
+	if !slices.Equal(info.CompressionMethods, []CompressionMethod{0}) {
+		t.Errorf("compression methods are %v, expected only null", info.CompressionMethods)
+	}
+	// supported_versions is added after the given extensions
+	if types, expected := extensionTypesOf(info), []uint16{0, 10, 11, 13, 16, 45, 51, 0xff01, 43}; !slices.Equal(types, expected) {
+		t.Errorf("extensions are %v, expected %v", types, expected)
+	}
+	for _, ext := range info.Extensions {
+		if !ext.Valid() {
+			t.Errorf("extension %d (%s) is invalid", ext.Type, ext.Name)
+		}
+	}
+	if info.Info.ServerName == nil || *info.Info.ServerName != "example.com" {
+		t.Errorf("server name is %v, expected example.com", info.Info.ServerName)
+	}
+	if !slices.Equal(info.Info.Protocols, []string{"h2", "http/1.1"}) {
+		t.Errorf("protocols are %v, expected h2 and http/1.1", info.Info.Protocols)
+	}
+	if versions := info.Extension(43).Data.(*SupportedVersionsData).Versions; !slices.Equal(versions, []ProtocolVersion{0x0304, 0x0303}) {
+		t.Errorf("supported versions are %v, expected TLS 1.3 and TLS 1.2", versions)
+	}
+	if shares := info.Extension(51).Data.(*KeyShareData).Shares; len(shares) != 1 || shares[0].Group != 29 || len(shares[0].KeyExchange) != 32 {
+		t.Errorf("key shares are %v, expected one 32 byte x25519 share", shares)
+	}
+	if lint := Lint(info); len(lint) != 0 {
+		t.Errorf("built ClientHello has lint findings: %v", lint)
+	}
+}
+
+func TestBuilderVersions(t *testing.T) {
+	tests := []struct {
+		min, max          ProtocolVersion
+		legacyVersion     ProtocolVersion
+		supportedVersions []ProtocolVersion // nil if there should be no supported_versions extension
+	}{
+		{0, 0, 0x0303, []ProtocolVersion{0x0304, 0x0303}},
+		{0x0304, 0x0304, 0x0303, []ProtocolVersion{0x0304}},
+		{0x0301, 0x0304, 0x0303, []ProtocolVersion{0x0304, 0x0303, 0x0302, 0x0301}},
+		{0x0301, 0x0303, 0x0303, nil},
+		{0x0300, 0x0302, 0x0302, nil},
+	}
+	for _, test := range tests {
+		info, err := (&ClientHelloBuilder{MinVersion: test.min, MaxVersion: test.max}).Build()
+		if err != nil {
+			t.Errorf("%s-%s: %s", test.min, test.max, err)
+			continue
+		}
+		if info.Version != test.legacyVersion {
+			t.Errorf("%s-%s: legacy_version is %s, expected %s", test.min, test.max, info.Version, test.legacyVersion)
+		}
+		ext := info.Extension(43)
+		if test.supportedVersions == nil {
+			if ext != nil {
+				t.Errorf("%s-%s: unexpected supported_versions extension", test.min, test.max)
+			}
+		} else if ext == nil {
+			t.Errorf("%s-%s: no supported_versions extension", test.min, test.max)
+		} else if versions := ext.Data.(*SupportedVersionsData).Versions; !slices.Equal(versions, test.supportedVersions) {
+			t.Errorf("%s-%s: supported versions are %v, expected %v", test.min, test.max, versions, test.supportedVersions)
+		}
+	}
+}
+
+func TestBuilderGrease(t *testing.T) {
+	builder := &ClientHelloBuilder{
+		CipherSuites: []uint16{0x1301},
+		Extensions: []BuilderExtension{
+			SupportedGroupsExtension(29),
+			KeyShareExtension(KeyShareEntry{Group: 29}),
+		},
+		Grease: true,
+	}
+	info, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.CipherSuites[0].Grease {
+		t.Errorf("first cipher suite is %04x, expected GREASE", info.CipherSuites[0].CodeUint16())
+	}
+	if first, last := info.Extensions[0], info.Extensions[len(info.Extensions)-1]; !first.Grease || !last.Grease {
+		t.Errorf("first and last extensions are %d and %d, expected GREASE", first.Type, last.Type)
+	}
+	if groups := info.Extension(10).Data.(*SupportedGroupsData).Groups; !isGreaseValue(groups[0]) || !slices.Equal(groups[1:], []uint16{29}) {
+		t.Errorf("supported groups are %v, expected GREASE and x25519", groups)
+	}
+	if shares := info.Extension(51).Data.(*KeyShareData).Shares; !isGreaseValue(shares[0].Group) || len(shares) != 2 {
+		t.Errorf("key shares are %v, expected GREASE and x25519", shares)
+	}
+	if versions := info.Extension(43).Data.(*SupportedVersionsData).Versions; !isGreaseValue(uint16(versions[0])) {
+		t.Errorf("supported versions are %v, expected GREASE first", versions)
+	}
+	if !sendsGrease(info) {
+		t.Error("sendsGrease is false")
+	}
+}
+
+func TestBuilderPadding(t *testing.T) {
+	pskExtension := BuilderExtension{Type: 41, Data: []byte{0, 1, 2}}
+	for _, extensions := range [][]BuilderExtension{nil, {ServerNameExtension("example.com"), pskExtension}} {
+		builder := &ClientHelloBuilder{MaxVersion: 0x0303, Extensions: extensions, PadTo: 512}
+		message, err := builder.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(message) != 512 {
+			t.Errorf("padded ClientHello is %d bytes, expected 512", len(message))
+		}
+		info := UnmarshalClientHello(message)
+		if info == nil {
+			t.Fatal("padded ClientHello does not parse")
+		}
+		types := extensionTypesOf(info)
+		if extensions == nil && !slices.Equal(types, []uint16{21}) {
+			t.Errorf("extensions are %v, expected only padding", types)
+		} else if extensions != nil && !slices.Equal(types, []uint16{0, 21, 41}) {
+			t.Errorf("extensions are %v, expected padding before pre_shared_key", types)
+		}
+	}
+}
+
+func TestBuilderErrors(t *testing.T) {
+	tests := []struct {
+		builder ClientHelloBuilder
+		err     string
+	}{
+		{ClientHelloBuilder{MaxVersion: 0xfefd}, "not an SSL or TLS version"},
+		{ClientHelloBuilder{MinVersion: 0xfeff, MaxVersion: 0x0303}, "not an SSL or TLS version"},
+		{ClientHelloBuilder{MinVersion: 0x0304, MaxVersion: 0x0303}, "greater than MaxVersion"},
+		{ClientHelloBuilder{Random: []byte{1, 2, 3}}, "Random is not 32 bytes long"},
+		{ClientHelloBuilder{SessionID: make([]byte, 33)}, "SessionID is longer than 32 bytes"},
+		{ClientHelloBuilder{Extensions: []BuilderExtension{ALPNExtension(strings.Repeat("x", 256))}}, "unable to encode extension 16"},
+	}
+	for _, test := range tests {
+		if _, err := test.builder.Marshal(); err == nil {
+			t.Errorf("Marshal succeeded, expected error containing %q", test.err)
+		} else if !strings.Contains(err.Error(), test.err) {
+			t.Errorf("Marshal returned error %q, expected error containing %q", err, test.err)
+		}
+	}
+}
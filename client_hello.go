@@ -27,9 +27,15 @@ package tlshacks
 
 import (
 	"encoding/json"
+	"fmt"
 	"golang.org/x/crypto/cryptobyte"
 )
 
+// isGreaseValue reports whether v is one of the reserved GREASE values from RFC 8701
+func isGreaseValue(v uint16) bool {
+	return (v&0x0F0F) == 0x0A0A && (v>>8) == (v&0xFF)
+}
+
 type ProtocolVersion uint16
 
 func (v ProtocolVersion) Hi() uint8 {
@@ -44,6 +50,33 @@ func (v ProtocolVersion) MarshalJSON() ([]byte, error) {
 	return json.Marshal([2]uint8{v.Hi(), v.Lo()})
 }
 
+func (v ProtocolVersion) String() string {
+	switch v {
+	case 0x0002:
+		return "SSL 2.0"
+	case 0x0300:
+		return "SSL 3.0"
+	case 0x0301:
+		return "TLS 1.0"
+	case 0x0302:
+		return "TLS 1.1"
+	case 0x0303:
+		return "TLS 1.2"
+	case 0x0304:
+		return "TLS 1.3"
+	case 0xfeff:
+		return "DTLS 1.0"
+	case 0xfefd:
+		return "DTLS 1.2"
+	case 0xfefc:
+		return "DTLS 1.3"
+	}
+	if isGreaseValue(uint16(v)) {
+		return "GREASE"
+	}
+	return fmt.Sprintf("0x%04x", uint16(v))
+}
+
 type CompressionMethod uint8
 
 func (m CompressionMethod) MarshalJSON() ([]byte, error) {
@@ -66,15 +99,51 @@ type ClientHelloInfo struct {
 	Extensions         []Extension         `json:"extensions"`
 
 	Info struct {
-		ServerName     *string  `json:"server_name"`
-		SCTs           bool     `json:"scts"`
-		Protocols      []string `json:"protocols"`
-		JA3String      string   `json:"ja3_string"`
-		JA3Fingerprint string   `json:"ja3_fingerprint"`
+		ServerName        *string   `json:"server_name"`
+		SCTs              bool      `json:"scts"`
+		OCSPStapling      bool      `json:"ocsp_stapling"`       // status_request or status_request_v2 with type ocsp
+		OCSPMultiStapling bool      `json:"ocsp_multi_stapling"` // status_request_v2 with type ocsp_multi
+		PostHandshakeAuth bool      `json:"post_handshake_auth"`
+		Protocols         []string  `json:"protocols"`
+		Resumption        string    `json:"resumption"` // see ResumptionType
+		JA3String         string    `json:"ja3_string"`
+		JA3Fingerprint    string    `json:"ja3_fingerprint"`
+		JA4String         string    `json:"ja4_string"`
+		JA4Fingerprint    string    `json:"ja4_fingerprint"`
+		Client            string    `json:"client"`
+		ClientMatches     []Match   `json:"client_matches"`
+		Lint              []Finding `json:"lint"`
 	} `json:"info"`
 }
 
+// Identify populates the client info using the given database, replacing any existing client info
+func (info *ClientHelloInfo) Identify(db *Database) {
+	info.Info.ClientMatches = db.Identify(info)
+	info.Info.Client = ""
+	if len(info.Info.ClientMatches) > 0 {
+		info.Info.Client = info.Info.ClientMatches[0].String()
+	}
+}
+
+// Extension returns the first extension of the given type, or nil if the ClientHello doesn't contain one
+func (info *ClientHelloInfo) Extension(extType uint16) *Extension {
+	for i := range info.Extensions {
+		if info.Extensions[i].Type == extType {
+			return &info.Extensions[i]
+		}
+	}
+	return nil
+}
+
+// UnmarshalClientHello parses a ClientHello handshake message using DefaultParser,
+// returning nil if it is malformed
 func UnmarshalClientHello(handshakeBytes []byte) *ClientHelloInfo {
+	return DefaultParser.UnmarshalClientHello(handshakeBytes)
+}
+
+// UnmarshalClientHello parses a ClientHello handshake message using p's extension
+// parsers, returning nil if it is malformed
+func (p *Parser) UnmarshalClientHello(handshakeBytes []byte) *ClientHelloInfo {
 	info := &ClientHelloInfo{Raw: handshakeBytes}
 	handshakeMessage := cryptobyte.String(handshakeBytes)
 
@@ -129,6 +198,7 @@ func UnmarshalClientHello(handshakeBytes []byte) *ClientHelloInfo {
 	info.Extensions = []Extension{}
 
 	if clientHello.Empty() {
+		info.populateInfo()
 		return info
 	}
 	var extensions cryptobyte.String
@@ -142,15 +212,11 @@ func UnmarshalClientHello(handshakeBytes []byte) *ClientHelloInfo {
 			return nil
 		}
 
-		parseData := extensionParsers[extType]
-		if parseData == nil {
-			parseData = ParseUnknownExtensionData
-		}
-		data := parseData(extData)
+		data := p.extensionParser(extType)(extData)
 
 		info.Extensions = append(info.Extensions, Extension{
 			Type:    extType,
-			Name:    Extensions[extType].Name,
+			Name:    ExtensionName(extType),
 			Grease:  Extensions[extType].Grease,
 			Private: Extensions[extType].Private,
 			Data:    data,
@@ -158,11 +224,34 @@ func UnmarshalClientHello(handshakeBytes []byte) *ClientHelloInfo {
 
 		switch extType {
 		case 0:
-			info.Info.ServerName = &data.(*ServerNameData).HostName
+			if d, ok := data.(*ServerNameData); ok {
+				info.Info.ServerName = &d.HostName
+			}
+		case 5:
+			if d, ok := data.(*StatusRequestData); ok && d.Valid && d.StatusType == 1 {
+				info.Info.OCSPStapling = true
+			}
+		case 17:
+			if d, ok := data.(*StatusRequestV2Data); ok && d.Valid {
+				for _, request := range d.Requests {
+					switch request.StatusType {
+					case 1:
+						info.Info.OCSPStapling = true
+					case 2:
+						info.Info.OCSPMultiStapling = true
+					}
+				}
+			}
 		case 16:
-			info.Info.Protocols = data.(*ALPNData).Protocols
+			if d, ok := data.(*ALPNData); ok {
+				info.Info.Protocols = d.Protocols
+			}
 		case 18:
 			info.Info.SCTs = true
+		case 49:
+			if d, ok := data.(*EmptyExtensionData); ok && d.Valid {
+				info.Info.PostHandshakeAuth = true
+			}
 		}
 
 	}
@@ -171,8 +260,17 @@ func UnmarshalClientHello(handshakeBytes []byte) *ClientHelloInfo {
 		return nil
 	}
 
+	info.populateInfo()
+	return info
+}
+
+// populateInfo fills in the parts of Info which are derived from the entire ClientHello
+func (info *ClientHelloInfo) populateInfo() {
+	info.Info.Resumption = ResumptionType(info)
 	info.Info.JA3String = JA3String(info)
 	info.Info.JA3Fingerprint = JA3Fingerprint(info.Info.JA3String)
-
-	return info
+	info.Info.JA4String = JA4String(info)
+	info.Info.JA4Fingerprint = JA4Fingerprint(info.Info.JA4String)
+	info.Info.Lint = Lint(info)
+	info.Identify(DefaultDatabase)
 }
@@ -0,0 +1,83 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"src.agwa.name/tlshacks"
+	"src.agwa.name/tlshacks/internal/helloinput"
+)
+
+func runDiff(args []string) {
+	flags := flag.NewFlagSet("tlshello diff", flag.ExitOnError)
+	var (
+		inputFormat  = flags.String("input", "auto", "Input format: "+helloinput.Formats)
+		outputFormat = flags.String("output", "text", "Output format: json or text")
+	)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: tlshello diff [flags] OLD NEW\n\n")
+		fmt.Fprintf(flags.Output(), "Explain how the ClientHello in NEW differs from the ClientHello in OLD.\n")
+		fmt.Fprintf(flags.Output(), "GREASE values, randoms, and key exchange values are ignored.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	var hellos [2]*tlshacks.ClientHelloInfo
+	for i, filename := range flags.Args() {
+		input, err := helloinput.ReadFile(filename)
+		if err != nil {
+			log.Fatal(err)
+		}
+		hellos[i], err = helloinput.DecodeClientHello(input, *inputFormat)
+		if err != nil {
+			log.Fatalf("%s: %s", filename, err)
+		}
+	}
+
+	diff := tlshacks.Diff(hellos[0], hellos[1])
+	switch *outputFormat {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "    ")
+		encoder.Encode(diff)
+	case "text":
+		fmt.Print(diff)
+	default:
+		log.Fatalf("unknown output format %q", *outputFormat)
+	}
+	if !diff.Empty() {
+		os.Exit(1)
+	}
+}
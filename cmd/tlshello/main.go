@@ -0,0 +1,135 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"src.agwa.name/tlshacks"
+	"src.agwa.name/tlshacks/internal/helloinput"
+)
+
+var commands = map[string]func([]string){
+	"diff": runDiff,
+	"pcap": runPcap,
+	"spec": runSpec,
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("tlshello: ")
+
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			command(os.Args[2:])
+			return
+		}
+	}
+	runDecode(os.Args[1:])
+}
+
+func runDecode(args []string) {
+	flags := flag.NewFlagSet("tlshello", flag.ExitOnError)
+	var (
+		inputFormat  = flags.String("input", "auto", "Input format: "+helloinput.Formats)
+		outputFormat = flags.String("output", "json", "Output format: json, text, hexdump, or signature (for adding to a client database)")
+		databaseFile = flags.String("db", "", "Identify clients using this database instead of the built-in one")
+		onlyJA3      = flags.Bool("ja3", false, "Print only the JA3 fingerprint")
+		onlyJA4      = flags.Bool("ja4", false, "Print only the JA4 fingerprint")
+	)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: tlshello [flags] [FILE...]\n")
+		fmt.Fprintf(flags.Output(), "       tlshello diff [flags] OLD NEW\n")
+		fmt.Fprintf(flags.Output(), "       tlshello pcap [flags] CAPTURE...\n")
+		fmt.Fprintf(flags.Output(), "       tlshello spec [flags] [FILE]\n\n")
+		fmt.Fprintf(flags.Output(), "Decode ClientHellos from FILEs (or standard input), which contain either a\n")
+		fmt.Fprintf(flags.Output(), "handshake message or TLS records, encoded as hex, base64, or binary.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if *outputFormat != "json" && *outputFormat != "text" && *outputFormat != "hexdump" && *outputFormat != "signature" {
+		log.Fatalf("unknown output format %q", *outputFormat)
+	}
+
+	var database *tlshacks.Database
+	if *databaseFile != "" {
+		var err error
+		database, err = tlshacks.LoadDatabaseFile(*databaseFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	filenames := flags.Args()
+	if len(filenames) == 0 {
+		filenames = []string{"-"}
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "    ")
+
+	exitCode := 0
+	for _, filename := range filenames {
+		input, err := helloinput.ReadFile(filename)
+		if err != nil {
+			log.Print(err)
+			exitCode = 1
+			continue
+		}
+		info, err := helloinput.DecodeClientHello(input, *inputFormat)
+		if err != nil {
+			log.Printf("%s: %s", filename, err)
+			exitCode = 1
+			continue
+		}
+		if database != nil {
+			info.Identify(database)
+		}
+
+		if *onlyJA3 || *onlyJA4 {
+			if *onlyJA3 {
+				fmt.Println(info.Info.JA3Fingerprint)
+			}
+			if *onlyJA4 {
+				fmt.Println(info.Info.JA4Fingerprint)
+			}
+		} else if *outputFormat == "text" {
+			writeText(os.Stdout, info)
+		} else if *outputFormat == "hexdump" {
+			tlshacks.WriteHexDump(os.Stdout, info.Raw, tlshacks.AnnotateClientHello(info))
+		} else if *outputFormat == "signature" {
+			encoder.Encode(tlshacks.SignatureOf(info, "", ""))
+		} else {
+			encoder.Encode(info)
+		}
+	}
+	os.Exit(exitCode)
+}
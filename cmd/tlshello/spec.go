@@ -0,0 +1,82 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"src.agwa.name/tlshacks"
+	"src.agwa.name/tlshacks/internal/helloinput"
+)
+
+func runSpec(args []string) {
+	flags := flag.NewFlagSet("tlshello spec", flag.ExitOnError)
+	var (
+		inputFormat  = flags.String("input", "auto", "Input format: "+helloinput.Formats)
+		outputFormat = flags.String("output", "go", "Output format: go (uTLS ClientHelloSpec) or json")
+	)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: tlshello spec [flags] [FILE]\n\n")
+		fmt.Fprintf(flags.Output(), "Convert the ClientHello in FILE (or standard input) into a specification\n")
+		fmt.Fprintf(flags.Output(), "suitable for replaying it with uTLS.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() > 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	filename := "-"
+	if flags.NArg() == 1 {
+		filename = flags.Arg(0)
+	}
+
+	input, err := helloinput.ReadFile(filename)
+	if err != nil {
+		log.Fatal(err)
+	}
+	info, err := helloinput.DecodeClientHello(input, *inputFormat)
+	if err != nil {
+		log.Fatalf("%s: %s", filename, err)
+	}
+
+	spec := tlshacks.MakeClientHelloSpec(info)
+	switch *outputFormat {
+	case "go":
+		fmt.Printf("// ClientHelloSpec for JA4 %s\n", info.Info.JA4Fingerprint)
+		fmt.Print(spec.GoSource())
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "    ")
+		encoder.Encode(spec)
+	default:
+		log.Fatalf("unknown output format %q", *outputFormat)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"src.agwa.name/tlshacks"
+)
+
+func nameOrUnknown(name string, grease bool) string {
+	if grease {
+		return "GREASE"
+	} else if name == "" {
+		return "unknown"
+	} else {
+		return name
+	}
+}
+
+// writeValue writes a parsed extension field, using its JSON name as the label
+func writeValue(w io.Writer, indent string, label string, value reflect.Value) {
+	if stringer, ok := value.Interface().(fmt.Stringer); ok {
+		fmt.Fprintf(w, "%s%s: %s\n", indent, label, stringer)
+		return
+	}
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			fmt.Fprintf(w, "%s%s: none\n", indent, label)
+		} else {
+			writeValue(w, indent, label, value.Elem())
+		}
+	case reflect.Struct:
+		fmt.Fprintf(w, "%s%s:\n", indent, label)
+		writeFields(w, indent+"    ", value)
+	case reflect.Slice, reflect.Array:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			fmt.Fprintf(w, "%s%s: %s\n", indent, label, hex.EncodeToString(value.Bytes()))
+		} else if elemKind := value.Type().Elem().Kind(); elemKind == reflect.Struct || elemKind == reflect.Pointer || elemKind == reflect.Slice {
+			fmt.Fprintf(w, "%s%s (%d):\n", indent, label, value.Len())
+			for i := 0; i < value.Len(); i++ {
+				writeValue(w, indent+"    ", fmt.Sprint(i), value.Index(i))
+			}
+		} else {
+			elems := make([]string, value.Len())
+			for i := range elems {
+				elems[i] = fmt.Sprint(value.Index(i).Interface())
+			}
+			fmt.Fprintf(w, "%s%s: %s\n", indent, label, strings.Join(elems, ", "))
+		}
+	default:
+		fmt.Fprintf(w, "%s%s: %v\n", indent, label, value.Interface())
+	}
+}
+
+func writeFields(w io.Writer, indent string, value reflect.Value) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		label, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if label == "" {
+			label = field.Name
+		} else if label == "raw" || label == "-" {
+			continue
+		}
+		writeValue(w, indent, label, value.Field(i))
+	}
+}
+
+func writeText(w io.Writer, info *tlshacks.ClientHelloInfo) {
+	fmt.Fprintf(w, "ClientHello (%d bytes)\n", len(info.Raw))
+	fmt.Fprintf(w, "    Version: %s (0x%04x)\n", info.Version, uint16(info.Version))
+	fmt.Fprintf(w, "    Random: %x\n", info.Random)
+	fmt.Fprintf(w, "    Session ID: %x\n", info.SessionID)
+
+	fmt.Fprintf(w, "    Cipher Suites (%d):\n", len(info.CipherSuites))
+	for _, suite := range info.CipherSuites {
+		fmt.Fprintf(w, "        0x%04x %s\n", suite.CodeUint16(), nameOrUnknown(suite.Name, suite.Grease))
+	}
+
+	fmt.Fprintf(w, "    Compression Methods (%d):\n", len(info.CompressionMethods))
+	for _, method := range info.CompressionMethods {
+		fmt.Fprintf(w, "        %d\n", method)
+	}
+
+	fmt.Fprintf(w, "    Extensions (%d):\n", len(info.Extensions))
+	for _, ext := range info.Extensions {
+		fmt.Fprintf(w, "        0x%04x %s\n", ext.Type, nameOrUnknown(ext.Name, ext.Grease))
+		switch data := ext.Data.(type) {
+		case *tlshacks.UnknownExtensionData:
+			if len(data.Raw) > 0 {
+				fmt.Fprintf(w, "            data: %x\n", data.Raw)
+			}
+		default:
+			if value := reflect.ValueOf(data); value.Kind() == reflect.Pointer && value.Elem().Kind() == reflect.Struct {
+				writeFields(w, "            ", value.Elem())
+			}
+		}
+	}
+
+	writeValue(w, "    ", "Info", reflect.ValueOf(info.Info))
+}
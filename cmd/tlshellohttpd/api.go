@@ -0,0 +1,128 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"src.agwa.name/tlshacks"
+	"src.agwa.name/tlshacks/internal/hellolog"
+)
+
+// helloLog stores every received ClientHello, if enabled
+var helloLog *hellolog.Log
+
+// maxQueryLimit is the maximum number of records returned by /api/hellos
+const maxQueryLimit = 1000
+
+// recordHello is a listener hook which records the connection's ClientHello in helloLog.
+// It runs on the connection's own goroutine as soon as the ClientHello has been read,
+// so hellos are recorded even if the handshake fails.
+func recordHello(conn *tlshacks.Conn, elapsed time.Duration) {
+	record := &hellolog.Record{
+		Time:       time.Now().UTC(),
+		RemoteAddr: conn.RemoteAddr().String(),
+		Raw:        conn.ClientHello,
+	}
+	if info := tlshacks.UnmarshalClientHello(conn.ClientHello); info != nil {
+		if info.Info.ServerName != nil {
+			record.ServerName = *info.Info.ServerName
+		}
+		record.JA3 = info.Info.JA3Fingerprint
+		record.JA4 = info.Info.JA4Fingerprint
+	}
+	if err := helloLog.Append(record); err != nil {
+		log.Printf("error storing ClientHello from %s: %s", record.RemoteAddr, err)
+	}
+}
+
+// registerAPI adds the hello log API to the admin listener's mux
+func registerAPI(mux *http.ServeMux) {
+	mux.HandleFunc("/api/hellos", hellosHandler)
+	mux.HandleFunc("/api/stats", statsHandler)
+}
+
+func writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "    ")
+	encoder.Encode(value)
+}
+
+func hellosHandler(w http.ResponseWriter, req *http.Request) {
+	params := req.URL.Query()
+	now := time.Now()
+	query := hellolog.Query{
+		JA3:   params.Get("ja3"),
+		JA4:   params.Get("ja4"),
+		Limit: 100,
+	}
+	if since := params.Get("since"); since != "" {
+		var err error
+		if query.Since, err = hellolog.ParseTime(since, now); err != nil {
+			http.Error(w, "Invalid since parameter (should be RFC 3339 time or duration like 24h)", http.StatusBadRequest)
+			return
+		}
+	}
+	if until := params.Get("until"); until != "" {
+		var err error
+		if query.Until, err = hellolog.ParseTime(until, now); err != nil {
+			http.Error(w, "Invalid until parameter (should be RFC 3339 time or duration like 24h)", http.StatusBadRequest)
+			return
+		}
+	}
+	if limit := params.Get("limit"); limit != "" {
+		var err error
+		if query.Limit, err = strconv.Atoi(limit); err != nil || query.Limit <= 0 || query.Limit > maxQueryLimit {
+			http.Error(w, "Invalid limit parameter (should be between 1 and "+strconv.Itoa(maxQueryLimit)+")", http.StatusBadRequest)
+			return
+		}
+	}
+
+	records, err := helloLog.Query(query)
+	if err != nil {
+		log.Printf("error querying hello log: %s", err)
+		http.Error(w, "Internal error reading hello log", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, records)
+}
+
+func statsHandler(w http.ResponseWriter, req *http.Request) {
+	var since time.Time
+	if param := req.URL.Query().Get("since"); param != "" {
+		var err error
+		if since, err = hellolog.ParseTime(param, time.Now()); err != nil {
+			http.Error(w, "Invalid since parameter (should be RFC 3339 time or duration like 24h)", http.StatusBadRequest)
+			return
+		}
+	}
+	writeJSON(w, helloLog.Stats(since))
+}
@@ -0,0 +1,74 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package main
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+
+	"src.agwa.name/tlshacks"
+)
+
+// exportHandlers serve a single aspect of the ClientHello as plain text (or binary),
+// for scripts which don't want to parse the JSON
+var exportHandlers = map[string]func(http.ResponseWriter, *tlshacks.ClientHelloInfo){
+	"/ja3":        func(w http.ResponseWriter, info *tlshacks.ClientHelloInfo) { writeLine(w, info.Info.JA3Fingerprint) },
+	"/ja3/string": func(w http.ResponseWriter, info *tlshacks.ClientHelloInfo) { writeLine(w, info.Info.JA3String) },
+	"/ja4":        func(w http.ResponseWriter, info *tlshacks.ClientHelloInfo) { writeLine(w, info.Info.JA4Fingerprint) },
+	"/ja4/string": func(w http.ResponseWriter, info *tlshacks.ClientHelloInfo) { writeLine(w, info.Info.JA4String) },
+	"/hex": func(w http.ResponseWriter, info *tlshacks.ClientHelloInfo) {
+		writeLine(w, hex.EncodeToString(info.Raw))
+	},
+	"/base64": func(w http.ResponseWriter, info *tlshacks.ClientHelloInfo) {
+		writeLine(w, base64.StdEncoding.EncodeToString(info.Raw))
+	},
+	"/pem": func(w http.ResponseWriter, info *tlshacks.ClientHelloInfo) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		pem.Encode(w, &pem.Block{Type: "TLS CLIENT HELLO", Bytes: info.Raw})
+	},
+	"/raw": func(w http.ResponseWriter, info *tlshacks.ClientHelloInfo) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", `attachment; filename="clienthello.bin"`)
+		w.Write(info.Raw)
+	},
+}
+
+func writeLine(w http.ResponseWriter, line string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, line)
+}
+
+// setCORSHeaders allows test pages on any origin to fetch the results with JavaScript.
+// No credentials are involved, since the response depends only on the TLS connection.
+func setCORSHeaders(w http.ResponseWriter) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Accept")
+	w.Header().Set("Access-Control-Max-Age", "86400")
+}
@@ -0,0 +1,98 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+
+	"src.agwa.name/tlshacks"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+var templates = template.Must(template.New("").Funcs(template.FuncMap{
+	"hex": func(b []byte) string {
+		return strings.TrimSpace(fmt.Sprintf("% x", b))
+	},
+	"indent": func(depth int) string {
+		return strings.Repeat("  ", depth)
+	},
+	"weakCipher":       weakCipher,
+	"extensionSummary": extensionSummary,
+	"percent":          func(f float64) string { return fmt.Sprintf("%.0f%%", f*100) },
+}).ParseFS(templateFS, "templates/*.html"))
+
+// weakCipherComponents are substrings of cipher suite names which indicate
+// broken or deprecated algorithms
+var weakCipherComponents = []string{"_NULL_", "_EXPORT", "_anon_", "_RC4_", "_RC2_", "_DES", "_3DES_", "_IDEA_", "_MD5"}
+
+func weakCipher(suite tlshacks.CipherSuite) bool {
+	for _, component := range weakCipherComponents {
+		if strings.Contains(suite.Name, component) {
+			return true
+		}
+	}
+	return strings.HasSuffix(suite.Name, "_NULL")
+}
+
+// extensionSummary returns the parsed extension data as compact JSON, without the raw bytes
+func extensionSummary(ext tlshacks.Extension) string {
+	data, err := json.Marshal(ext.Data)
+	if err != nil {
+		return ""
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return string(data)
+	}
+	if len(fields) == 1 && fields["raw"] != nil {
+		// Unknown extension, so the raw bytes are all there is
+		var raw []byte
+		json.Unmarshal(fields["raw"], &raw)
+		return fmt.Sprintf("% x", raw)
+	}
+	delete(fields, "raw")
+	delete(fields, "valid")
+	if len(fields) == 0 {
+		return ""
+	}
+	summary, _ := json.Marshal(fields)
+	return string(summary)
+}
+
+func writeHTML(w http.ResponseWriter, name string, data any) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := templates.ExecuteTemplate(w, name, data); err != nil {
+		log.Printf("error executing template %s: %s", name, err)
+	}
+}
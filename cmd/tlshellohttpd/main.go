@@ -28,20 +28,52 @@ package main
 import (
 	"crypto/tls"
 	"encoding/json"
-	"golang.org/x/crypto/acme"
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
-	"strings"
-	"time"
 
 	"src.agwa.name/go-listener"
-	"src.agwa.name/go-listener/cert"
 	"src.agwa.name/tlshacks"
+	"src.agwa.name/tlshacks/internal/connlog"
+	"src.agwa.name/tlshacks/internal/hellolog"
+	"src.agwa.name/tlshacks/internal/metrics"
+	"src.agwa.name/tlshacks/internal/negotiate"
+	"src.agwa.name/tlshacks/internal/serverconfig"
 )
 
-func handler(w http.ResponseWriter, req *http.Request) {
-	if req.URL.Path != "/" {
+// serverName is the name of this server in the configuration file
+const serverName = "tlshellohttpd"
+
+var (
+	metricsRegistry = new(metrics.Registry)
+	listenerMetrics = metrics.NewListenerMetrics(metricsRegistry)
+)
+
+func handler(w http.ResponseWriter, req *http.Request, server *serverconfig.Server) {
+	setCORSHeaders(w)
+	if req.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !server.EndpointEnabled(req.URL.Path) {
+		http.NotFound(w, req)
+		return
+	} else if req.URL.Path == "/hexdump" {
+		hexdumpHandler(w, req)
+		return
+	} else if exportHandler, ok := exportHandlers[req.URL.Path]; ok {
+		clientHello := req.Context().Value(tlshacks.ClientHelloKey).([]byte)
+		info := tlshacks.UnmarshalClientHello(clientHello)
+		if info == nil {
+			http.Error(w, "Your ClientHello could not be parsed", http.StatusBadRequest)
+			return
+		}
+		exportHandler(w, info)
+		return
+	} else if req.URL.Path != "/" {
 		http.NotFound(w, req)
 		return
 	}
@@ -49,6 +81,12 @@ func handler(w http.ResponseWriter, req *http.Request) {
 	clientHello := req.Context().Value(tlshacks.ClientHelloKey).([]byte)
 	info := tlshacks.UnmarshalClientHello(clientHello)
 
+	w.Header().Set("Vary", "Accept")
+	if negotiate.Format(req, "json", "html") == "html" {
+		writeHTML(w, "index.html", info)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	encoder := json.NewEncoder(w)
 	encoder.SetIndent("", "    ")
@@ -56,38 +94,85 @@ func handler(w http.ResponseWriter, req *http.Request) {
 }
 
 func main() {
-	var (
-		hostnameOrCert = os.Args[1]
-		listenerArg    = os.Args[2]
-	)
-
-	tlsConfig := &tls.Config{
-		NextProtos:             []string{"h2", "http/1.1", acme.ALPNProto},
-		SessionTicketsDisabled: true,
+	flags := serverconfig.NewFlags(flag.CommandLine)
+	flags.ServerFlags(flag.CommandLine, serverName, "", "tlshellohttpd")
+	flag.Func("hello-log", "Store received ClientHellos in this file and serve them at /api/hellos and /api/stats on the admin listener", func(value string) error {
+		flags.SetServer(serverName, func(server *serverconfig.Server) { server.HelloLog = value })
+		return nil
+	})
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [HOSTNAME_OR_CERT_PATH LISTENER]\n\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "Serve information about clients' ClientHellos over HTTPS.  The certificate and\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "listener can be given as arguments, flags, or in the configuration file.\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	switch flag.NArg() {
+	case 0:
+	case 2:
+		flags.SetServer(serverName, func(server *serverconfig.Server) {
+			server.Cert = flag.Arg(0)
+			server.Listen = flag.Arg(1)
+		})
+	default:
+		flag.Usage()
+		os.Exit(2)
 	}
-	if strings.HasPrefix(hostnameOrCert, "/") {
-		// assume it's a path to a certificate
-		tlsConfig.GetCertificate = cert.GetCertificateFromFile(hostnameOrCert)
-	} else {
-		// assume it's a hostname
-		tlsConfig.GetCertificate = cert.GetCertificateAutomatically([]string{hostnameOrCert})
+
+	config, err := flags.Config()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := config.SetupLogging(); err != nil {
+		log.Fatal(err)
+	}
+	server := config.Server(serverName)
+	if server == nil {
+		log.Fatal("no listener specified (use LISTENER argument, -listen flag, or configuration file)")
+	}
+	if err := server.Validate(); err != nil {
+		log.Fatal(err)
 	}
 
-	httpServer := &http.Server{
-		ReadTimeout:  5 * time.Second,
-		WriteTimeout: 10 * time.Second,
-		IdleTimeout:  5 * time.Second,
-		Handler:      http.HandlerFunc(handler),
-		ConnContext:  tlshacks.ConnContext,
+	tlsConfig, err := server.TLSConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	tlsConfig.SessionTicketsDisabled = true
+	tlsConfig.GetCertificate = server.GetCertificate()
+
+	httpServer := server.HTTPServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		handler(w, req, server)
+	}))
+	httpServer.ConnContext = tlshacks.ConnContext
+	hooks := listenerMetrics.Hooks()
+	if logger := config.Logger(serverName); logger != nil {
+		connLogger := connlog.New(logger)
+		httpServer.ConnState = connLogger.ConnState
+		hooks = tlshacks.CombineListenerHooks(hooks, connLogger.Hooks())
+	}
+	adminMux := http.NewServeMux()
+	adminMux.Handle("/metrics", metricsRegistry)
+	if server.HelloLog != "" {
+		helloLog, err = hellolog.Open(server.HelloLog)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer helloLog.Close()
+		hooks = tlshacks.CombineListenerHooks(hooks, &tlshacks.ListenerHooks{ClientHello: recordHello})
+		registerAPI(adminMux)
+	}
+	if err := config.ServeAdmin(adminMux); err != nil {
+		log.Fatal(err)
 	}
-	httpServer.SetKeepAlivesEnabled(false)
 
-	streamListener, err := listener.Open(listenerArg)
+	streamListener, err := listener.Open(server.Listen)
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer streamListener.Close()
 
-	tlsListener := tls.NewListener(tlshacks.NewListener(streamListener), tlsConfig)
+	tlsListener := tls.NewListener(tlshacks.NewListenerWithHooks(streamListener, hooks), tlsConfig)
 	log.Fatal(httpServer.Serve(tlsListener))
 }
@@ -0,0 +1,136 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"time"
+
+	"src.agwa.name/tlshacks"
+	"src.agwa.name/tlshacks/internal/helloinput"
+)
+
+func writeText(w io.Writer, result *tlshacks.ReplayResult) {
+	if result.ServerHello != nil {
+		writeServerHello(w, result.ServerHello)
+	}
+	for _, message := range result.Messages {
+		fmt.Fprintf(w, "%s (%d bytes)\n", message.Name, len(message.Raw))
+	}
+	if result.Abbreviated {
+		fmt.Fprintf(w, "Server resumed the session (abbreviated handshake)\n")
+	}
+	if result.Alert != nil {
+		fmt.Fprintf(w, "Server sent %s\n", result.Alert)
+	}
+}
+
+func writeServerHello(w io.Writer, serverHello *tlshacks.ServerHelloInfo) {
+	if serverHello.Info.HelloRetryRequest {
+		fmt.Fprintf(w, "HelloRetryRequest\n")
+	} else {
+		fmt.Fprintf(w, "ServerHello\n")
+	}
+	fmt.Fprintf(w, "    Version: %s\n", serverHello.Info.Version)
+	fmt.Fprintf(w, "    Cipher Suite: 0x%04x %s\n", serverHello.CipherSuite.CodeUint16(), serverHello.CipherSuite.Name)
+	if serverHello.Info.Group != nil {
+		fmt.Fprintf(w, "    Group: 0x%04x\n", *serverHello.Info.Group)
+	}
+	if serverHello.Info.Protocol != nil {
+		fmt.Fprintf(w, "    ALPN: %s\n", *serverHello.Info.Protocol)
+	}
+	fmt.Fprintf(w, "    Extensions (%d):\n", len(serverHello.Extensions))
+	for _, ext := range serverHello.Extensions {
+		fmt.Fprintf(w, "        0x%04x %s\n", ext.Type, ext.Name)
+	}
+}
+
+func main() {
+	var (
+		inputFormat  = flag.String("input", "auto", "Input format: "+helloinput.Formats)
+		outputFormat = flag.String("output", "text", "Output format: json or text")
+		timeout      = flag.Duration("timeout", 10*time.Second, "Time limit for connecting and receiving the response")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] ADDRESS [FILE]\n\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "Send the ClientHello in FILE (or standard input) verbatim to ADDRESS (host:port)\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "and print the server's response.\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	log.SetFlags(0)
+	log.SetPrefix("tlsreplay: ")
+
+	if flag.NArg() < 1 || flag.NArg() > 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	address := flag.Arg(0)
+	filename := "-"
+	if flag.NArg() == 2 {
+		filename = flag.Arg(1)
+	}
+
+	input, err := helloinput.ReadFile(filename)
+	if err != nil {
+		log.Fatal(err)
+	}
+	clientHello, err := helloinput.Decode(input, *inputFormat)
+	if err != nil {
+		log.Fatalf("%s: %s", filename, err)
+	}
+
+	conn, err := net.DialTimeout("tcp", address, *timeout)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(*timeout)); err != nil {
+		log.Fatal(err)
+	}
+
+	result, err := tlshacks.Replay(conn, clientHello)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch *outputFormat {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "    ")
+		encoder.Encode(result)
+	case "text":
+		writeText(os.Stdout, result)
+	default:
+		log.Fatalf("unknown output format %q", *outputFormat)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"src.agwa.name/tlshacks/scanner"
+)
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func writeText(w io.Writer, report *scanner.Report) {
+	fmt.Fprintf(w, "Scan of %s\n", report.Address)
+	if report.ServerName != "" {
+		fmt.Fprintf(w, "    Server Name: %s\n", report.ServerName)
+	}
+	for _, version := range report.Versions {
+		if !version.Supported {
+			fmt.Fprintf(w, "%s: not supported\n", version.VersionName)
+			continue
+		}
+		fmt.Fprintf(w, "%s: supported\n", version.VersionName)
+		fmt.Fprintf(w, "    Cipher Suites (server preference: %s):\n", yesNo(version.ServerPreference))
+		for _, suite := range version.CipherSuites {
+			fmt.Fprintf(w, "        0x%04x %s\n", suite.CodeUint16(), suite.Name)
+		}
+		if len(version.Groups) > 0 {
+			fmt.Fprintf(w, "    Groups:\n")
+			for _, group := range version.Groups {
+				fmt.Fprintf(w, "        %s\n", group)
+			}
+		}
+		if len(version.SignatureAlgorithms) > 0 {
+			fmt.Fprintf(w, "    Signature Algorithms:\n")
+			for _, algorithm := range version.SignatureAlgorithms {
+				fmt.Fprintf(w, "        %s\n", algorithm)
+			}
+		}
+	}
+	fmt.Fprintf(w, "ALPN: %s\n", strings.Join(report.Protocols, ", "))
+	fmt.Fprintf(w, "HelloRetryRequest: %s (cookie: %s)\n", yesNo(report.HelloRetryRequest), yesNo(report.HelloRetryRequestCookie))
+	fmt.Fprintf(w, "Resumption:\n")
+	fmt.Fprintf(w, "    TLS 1.2 Session Ticket: %s\n", yesNo(report.Resumption.TLS12SessionTicket))
+	fmt.Fprintf(w, "    TLS 1.3 PSK: %s\n", yesNo(report.Resumption.TLS13PSK))
+	fmt.Fprintf(w, "ECH:\n")
+	fmt.Fprintf(w, "    GREASE Accepted: %s\n", yesNo(report.ECH.GreaseAccepted))
+	fmt.Fprintf(w, "    Retry Configs: %s\n", yesNo(len(report.ECH.RetryConfigs) > 0))
+}
+
+func main() {
+	var (
+		outputFormat = flag.String("output", "text", "Output format: json or text")
+		serverName   = flag.String("servername", "", "Server name to send in SNI (defaults to the host in ADDRESS)")
+		protocols    = flag.String("alpn", strings.Join(scanner.DefaultProtocols, ","), "Comma-separated list of ALPN protocols to probe for")
+		timeout      = flag.Duration("timeout", 10*time.Second, "Time limit for each connection")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] ADDRESS\n\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "Probe the TLS server at ADDRESS (host:port) and report its capabilities.\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	log.SetFlags(0)
+	log.SetPrefix("tlsscan: ")
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	config := &scanner.Config{
+		ServerName: *serverName,
+		Timeout:    *timeout,
+		Protocols:  []string{},
+	}
+	if *protocols != "" {
+		config.Protocols = strings.Split(*protocols, ",")
+	}
+
+	report, err := scanner.Scan(context.Background(), flag.Arg(0), config)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch *outputFormat {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "    ")
+		encoder.Encode(report)
+	case "text":
+		writeText(os.Stdout, report)
+	default:
+		log.Fatalf("unknown output format %q", *outputFormat)
+	}
+}
@@ -0,0 +1,396 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"golang.org/x/crypto/cryptobyte"
+	"src.agwa.name/tlshacks"
+)
+
+// expiryWarningPeriod is how far in advance of a certificate's expiration to warn
+const expiryWarningPeriod = 30 * 24 * time.Hour
+
+// RFC 6962, Section 3.3
+var oidExtensionSCTList = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 2}
+
+type certReport struct {
+	Policy        *clientcertPolicy   `json:"policy"`
+	Certificates  []certInfo          `json:"certificates"`
+	ChainProblems []string            `json:"chain_problems"`
+	Verification  *verificationResult `json:"verification,omitempty"` // only if a CA bundle is configured and a certificate was sent
+	ClientFlight  *flightReport       `json:"client_flight,omitempty"`
+
+	// PolicyViolations are the reasons the request was rejected, if it was
+	PolicyViolations []string `json:"policy_violations"`
+}
+
+// flightReport describes the handshake messages which the client sent after the ClientHello.
+// They are only visible in TLS 1.2 and below; TLS 1.3 encrypts them.
+type flightReport struct {
+	Version            string                      `json:"version"`
+	Encrypted          bool                        `json:"encrypted"` // true if TLS 1.3, in which case the other fields are empty
+	Sequence           []string                    `json:"sequence"`
+	CertificateMessage []byte                      `json:"certificate_message"` // raw Certificate message, including header
+	CertificateVerify  *tlshacks.CertificateVerify `json:"certificate_verify"`
+	AlgorithmPermitted *bool                       `json:"algorithm_permitted"` // nil if unrestricted or unknown
+	Problems           []string                    `json:"problems"`
+}
+
+type certInfo struct {
+	Subject                string            `json:"subject"`
+	Issuer                 string            `json:"issuer"`
+	SerialNumber           string            `json:"serial_number"`
+	SHA256                 string            `json:"sha256"`
+	PublicKeySHA256        string            `json:"public_key_sha256"`
+	DNSNames               []string          `json:"dns_names"`
+	EmailAddresses         []string          `json:"email_addresses"`
+	IPAddresses            []string          `json:"ip_addresses"`
+	URIs                   []string          `json:"uris"`
+	KeyType                string            `json:"key_type"`
+	KeySize                int               `json:"key_size"` // in bits
+	SignatureAlgorithm     string            `json:"signature_algorithm"`
+	NotBefore              time.Time         `json:"not_before"`
+	NotAfter               time.Time         `json:"not_after"`
+	KeyUsage               []string          `json:"key_usage"`
+	ExtKeyUsage            []string          `json:"ext_key_usage"`
+	BasicConstraints       *basicConstraints `json:"basic_constraints"` // nil if absent
+	Policies               []string          `json:"policies"`
+	OCSPServers            []string          `json:"ocsp_servers"`
+	IssuingCertificateURLs []string          `json:"issuing_certificate_urls"`
+	CRLDistributionPoints  []string          `json:"crl_distribution_points"`
+	SCTs                   []sctInfo         `json:"scts"`
+	Warnings               []string          `json:"warnings"`
+	PEM                    string            `json:"pem"`
+}
+
+type basicConstraints struct {
+	CA         bool `json:"ca"`
+	MaxPathLen *int `json:"max_path_len"` // nil if unlimited
+}
+
+type sctInfo struct {
+	Version   uint8     `json:"version"`
+	LogID     string    `json:"log_id"` // base64, as in CT log lists
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func (sct sctInfo) VersionName() string {
+	return fmt.Sprintf("v%d", sct.Version+1)
+}
+
+type verificationResult struct {
+	Verified bool       `json:"verified"`
+	Error    string     `json:"error,omitempty"`
+	Chains   [][]string `json:"chains"` // subjects of each verified chain, from leaf to root
+}
+
+var keyUsageNames = []struct {
+	usage x509.KeyUsage
+	name  string
+}{
+	{x509.KeyUsageDigitalSignature, "digital_signature"},
+	{x509.KeyUsageContentCommitment, "content_commitment"},
+	{x509.KeyUsageKeyEncipherment, "key_encipherment"},
+	{x509.KeyUsageDataEncipherment, "data_encipherment"},
+	{x509.KeyUsageKeyAgreement, "key_agreement"},
+	{x509.KeyUsageCertSign, "cert_sign"},
+	{x509.KeyUsageCRLSign, "crl_sign"},
+	{x509.KeyUsageEncipherOnly, "encipher_only"},
+	{x509.KeyUsageDecipherOnly, "decipher_only"},
+}
+
+var extKeyUsageNames = map[x509.ExtKeyUsage]string{
+	x509.ExtKeyUsageAny:                            "any",
+	x509.ExtKeyUsageServerAuth:                     "server_auth",
+	x509.ExtKeyUsageClientAuth:                     "client_auth",
+	x509.ExtKeyUsageCodeSigning:                    "code_signing",
+	x509.ExtKeyUsageEmailProtection:                "email_protection",
+	x509.ExtKeyUsageIPSECEndSystem:                 "ipsec_end_system",
+	x509.ExtKeyUsageIPSECTunnel:                    "ipsec_tunnel",
+	x509.ExtKeyUsageIPSECUser:                      "ipsec_user",
+	x509.ExtKeyUsageTimeStamping:                   "time_stamping",
+	x509.ExtKeyUsageOCSPSigning:                    "ocsp_signing",
+	x509.ExtKeyUsageMicrosoftServerGatedCrypto:     "microsoft_server_gated_crypto",
+	x509.ExtKeyUsageNetscapeServerGatedCrypto:      "netscape_server_gated_crypto",
+	x509.ExtKeyUsageMicrosoftCommercialCodeSigning: "microsoft_commercial_code_signing",
+	x509.ExtKeyUsageMicrosoftKernelCodeSigning:     "microsoft_kernel_code_signing",
+}
+
+func keyInfo(cert *x509.Certificate) (string, int) {
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return "RSA", key.N.BitLen()
+	case *ecdsa.PublicKey:
+		return "ECDSA " + key.Curve.Params().Name, key.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		return "Ed25519", 256
+	default:
+		return cert.PublicKeyAlgorithm.String(), 0
+	}
+}
+
+// parseSCTs parses the SignedCertificateTimestampList embedded in the certificate
+func parseSCTs(cert *x509.Certificate) ([]sctInfo, error) {
+	scts := []sctInfo{}
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(oidExtensionSCTList) {
+			continue
+		}
+		var listBytes []byte
+		if _, err := asn1.Unmarshal(ext.Value, &listBytes); err != nil {
+			return nil, fmt.Errorf("SCT extension is not an OCTET STRING: %w", err)
+		}
+		var list cryptobyte.String
+		input := cryptobyte.String(listBytes)
+		if !input.ReadUint16LengthPrefixed(&list) || !input.Empty() {
+			return nil, fmt.Errorf("SCT list is malformed")
+		}
+		for !list.Empty() {
+			var (
+				sct       cryptobyte.String
+				info      sctInfo
+				logID     []byte
+				timestamp uint64
+			)
+			if !list.ReadUint16LengthPrefixed(&sct) || !sct.ReadUint8(&info.Version) || !sct.ReadBytes(&logID, 32) || !sct.ReadUint64(&timestamp) {
+				return nil, fmt.Errorf("SCT is malformed")
+			}
+			info.LogID = base64.StdEncoding.EncodeToString(logID)
+			info.Timestamp = time.UnixMilli(int64(timestamp)).UTC()
+			scts = append(scts, info)
+		}
+	}
+	return scts, nil
+}
+
+func makeCertInfo(cert *x509.Certificate, now time.Time) certInfo {
+	fingerprint := sha256.Sum256(cert.Raw)
+	keyFingerprint := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	info := certInfo{
+		Subject:                cert.Subject.String(),
+		Issuer:                 cert.Issuer.String(),
+		SerialNumber:           hex.EncodeToString(cert.SerialNumber.Bytes()),
+		SHA256:                 hex.EncodeToString(fingerprint[:]),
+		PublicKeySHA256:        hex.EncodeToString(keyFingerprint[:]),
+		DNSNames:               nonNil(cert.DNSNames),
+		EmailAddresses:         nonNil(cert.EmailAddresses),
+		IPAddresses:            []string{},
+		URIs:                   []string{},
+		SignatureAlgorithm:     cert.SignatureAlgorithm.String(),
+		NotBefore:              cert.NotBefore,
+		NotAfter:               cert.NotAfter,
+		KeyUsage:               []string{},
+		ExtKeyUsage:            []string{},
+		Policies:               []string{},
+		OCSPServers:            nonNil(cert.OCSPServer),
+		IssuingCertificateURLs: nonNil(cert.IssuingCertificateURL),
+		CRLDistributionPoints:  nonNil(cert.CRLDistributionPoints),
+		Warnings:               []string{},
+		PEM:                    string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})),
+	}
+	for _, ip := range cert.IPAddresses {
+		info.IPAddresses = append(info.IPAddresses, ip.String())
+	}
+	for _, uri := range cert.URIs {
+		info.URIs = append(info.URIs, uri.String())
+	}
+	info.KeyType, info.KeySize = keyInfo(cert)
+	for _, usage := range keyUsageNames {
+		if cert.KeyUsage&usage.usage != 0 {
+			info.KeyUsage = append(info.KeyUsage, usage.name)
+		}
+	}
+	for _, usage := range cert.ExtKeyUsage {
+		if name, ok := extKeyUsageNames[usage]; ok {
+			info.ExtKeyUsage = append(info.ExtKeyUsage, name)
+		} else {
+			info.ExtKeyUsage = append(info.ExtKeyUsage, fmt.Sprintf("unknown (%d)", usage))
+		}
+	}
+	for _, oid := range cert.UnknownExtKeyUsage {
+		info.ExtKeyUsage = append(info.ExtKeyUsage, oid.String())
+	}
+	if cert.BasicConstraintsValid {
+		info.BasicConstraints = &basicConstraints{CA: cert.IsCA}
+		if cert.MaxPathLen > 0 || cert.MaxPathLenZero {
+			info.BasicConstraints.MaxPathLen = &cert.MaxPathLen
+		}
+	}
+	for _, policy := range cert.Policies {
+		info.Policies = append(info.Policies, policy.String())
+	}
+
+	if scts, err := parseSCTs(cert); err != nil {
+		info.Warnings = append(info.Warnings, err.Error())
+	} else {
+		info.SCTs = scts
+	}
+
+	switch {
+	case now.Before(cert.NotBefore):
+		info.Warnings = append(info.Warnings, "Certificate is not yet valid")
+	case now.After(cert.NotAfter):
+		info.Warnings = append(info.Warnings, "Certificate has expired")
+	case now.Add(expiryWarningPeriod).After(cert.NotAfter):
+		info.Warnings = append(info.Warnings, fmt.Sprintf("Certificate expires in %d days", int(cert.NotAfter.Sub(now).Hours()/24)))
+	}
+	if info.KeyType == "RSA" && info.KeySize < 2048 {
+		info.Warnings = append(info.Warnings, fmt.Sprintf("RSA key is only %d bits", info.KeySize))
+	}
+	switch cert.SignatureAlgorithm {
+	case x509.MD2WithRSA, x509.MD5WithRSA, x509.SHA1WithRSA, x509.DSAWithSHA1, x509.ECDSAWithSHA1:
+		info.Warnings = append(info.Warnings, "Certificate is signed with a weak signature algorithm")
+	}
+	return info
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+// chainProblems checks that each certificate was issued by the next one, as required by RFC 8446, Section 4.4.2
+// and RFC 5246, Section 7.4.6
+func chainProblems(certs []*x509.Certificate) []string {
+	problems := []string{}
+	for i, cert := range certs {
+		for j := range i {
+			if bytes.Equal(certs[j].Raw, cert.Raw) {
+				problems = append(problems, fmt.Sprintf("Certificate %d is a duplicate of certificate %d", i+1, j+1))
+			}
+		}
+		if i+1 == len(certs) {
+			break
+		}
+		next := certs[i+1]
+		if !bytes.Equal(cert.RawIssuer, next.RawSubject) {
+			problems = append(problems, fmt.Sprintf("Certificate %d's issuer does not match the subject of certificate %d", i+1, i+2))
+		} else if err := cert.CheckSignatureFrom(next); err != nil {
+			problems = append(problems, fmt.Sprintf("Certificate %d was not signed by certificate %d: %s", i+1, i+2, err))
+		}
+	}
+	if len(certs) > 0 && certs[0].IsCA {
+		problems = append(problems, "The first certificate is a CA certificate")
+	}
+	return problems
+}
+
+func verifyChain(certs []*x509.Certificate, roots *x509.CertPool, now time.Time) *verificationResult {
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	chains, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	result := &verificationResult{Verified: err == nil, Chains: [][]string{}}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	for _, chain := range chains {
+		subjects := make([]string, len(chain))
+		for i, cert := range chain {
+			subjects[i] = cert.Subject.String()
+		}
+		result.Chains = append(result.Chains, subjects)
+	}
+	return result
+}
+
+func makeFlightReport(flight *tlshacks.ClientFlight, version uint16, policy *clientcertPolicy) *flightReport {
+	report := &flightReport{
+		Version:  tls.VersionName(version),
+		Sequence: []string{},
+		Problems: []string{},
+	}
+	if version >= tls.VersionTLS13 {
+		report.Encrypted = true
+		return report
+	}
+	report.Sequence = flight.Sequence
+	if !flight.Complete {
+		report.Problems = append(report.Problems, "Did not see the client's encrypted Finished message")
+	}
+	if message := flight.Message(tlshacks.HandshakeTypeCertificate); message != nil {
+		report.CertificateMessage = message.Raw
+		if _, err := tlshacks.ParseCertificateMessage(message); err != nil {
+			report.Problems = append(report.Problems, err.Error())
+		}
+	}
+	if message := flight.Message(tlshacks.HandshakeTypeCertificateVerify); message != nil {
+		verify, err := tlshacks.ParseCertificateVerify(message, version)
+		if err != nil {
+			report.Problems = append(report.Problems, err.Error())
+		}
+		report.CertificateVerify = verify
+		report.AlgorithmPermitted = policy.algorithmPermitted(verify)
+	}
+	return report
+}
+
+// makeCertReport analyzes the certificates sent by the client, verifying them against the policy's CA bundle.
+// flight, if non-nil, is what the client sent after the ClientHello, using the given TLS version.
+func makeCertReport(certs []*x509.Certificate, policy *clientcertPolicy, flight *tlshacks.ClientFlight, version uint16) *certReport {
+	now := time.Now()
+	report := &certReport{
+		Policy:           policy,
+		Certificates:     make([]certInfo, len(certs)),
+		ChainProblems:    chainProblems(certs),
+		PolicyViolations: []string{},
+	}
+	for i, cert := range certs {
+		report.Certificates[i] = makeCertInfo(cert, now)
+	}
+	if policy.roots != nil && len(certs) > 0 {
+		report.Verification = verifyChain(certs, policy.roots, now)
+	}
+	if flight != nil {
+		report.ClientFlight = makeFlightReport(flight, version, policy)
+		if permitted := report.ClientFlight.AlgorithmPermitted; permitted != nil && !*permitted {
+			report.PolicyViolations = append(report.PolicyViolations, fmt.Sprintf("CertificateVerify uses %s, which is not a permitted signature algorithm", report.ClientFlight.CertificateVerify.AlgorithmName))
+		}
+	}
+	return report
+}
@@ -26,72 +26,202 @@
 package main
 
 import (
-	"crypto/sha256"
 	"crypto/tls"
-	"crypto/x509"
-	"encoding/hex"
-	"encoding/pem"
+	"embed"
+	"encoding/json"
 	"fmt"
-	"golang.org/x/crypto/acme"
+	"html/template"
+	"io"
 	"log"
+	"log/slog"
 	"net/http"
+	"strings"
 	"time"
 
 	"src.agwa.name/go-listener"
+	"src.agwa.name/tlshacks"
+	"src.agwa.name/tlshacks/internal/connlog"
+	"src.agwa.name/tlshacks/internal/negotiate"
+	"src.agwa.name/tlshacks/internal/serverconfig"
 )
 
-func fingerprints(certs []*x509.Certificate) []string {
-	f := make([]string, len(certs))
-	for i, cert := range certs {
-		fp := sha256.Sum256(cert.Raw)
-		f[i] = hex.EncodeToString(fp[:])
+//go:embed templates
+var templateFS embed.FS
+
+var templates = template.Must(template.New("").Funcs(template.FuncMap{
+	"join":  strings.Join,
+	"inc":   func(i int) int { return i + 1 },
+	"deref": func(v *uint16) uint16 { return *v },
+}).ParseFS(templateFS, "templates/*.html"))
+
+func writeCertReportText(w io.Writer, report *certReport) {
+	joined := func(values []string) string {
+		if len(values) == 0 {
+			return "(none)"
+		}
+		return strings.Join(values, ", ")
+	}
+	if len(report.Certificates) == 0 {
+		fmt.Fprintf(w, "No client certificate was sent\n")
+	}
+	for i, cert := range report.Certificates {
+		if i > 0 {
+			fmt.Fprintf(w, "\n")
+		}
+		fmt.Fprintf(w, "Subject = %s\n", cert.Subject)
+		fmt.Fprintf(w, "Issuer = %s\n", cert.Issuer)
+		fmt.Fprintf(w, "Certificate SHA-256 = %s\n", cert.SHA256)
+		fmt.Fprintf(w, "Public Key SHA-256 = %s\n", cert.PublicKeySHA256)
+		fmt.Fprintf(w, "Serial Number = %s\n", cert.SerialNumber)
+		fmt.Fprintf(w, "DNS Names = %s\n", joined(cert.DNSNames))
+		fmt.Fprintf(w, "Email Addresses = %s\n", joined(cert.EmailAddresses))
+		fmt.Fprintf(w, "IP Addresses = %s\n", joined(cert.IPAddresses))
+		fmt.Fprintf(w, "URIs = %s\n", joined(cert.URIs))
+		fmt.Fprintf(w, "Key = %s (%d bits)\n", cert.KeyType, cert.KeySize)
+		fmt.Fprintf(w, "Signature Algorithm = %s\n", cert.SignatureAlgorithm)
+		fmt.Fprintf(w, "Not Before = %s\n", cert.NotBefore.UTC().Format(time.RFC3339))
+		fmt.Fprintf(w, "Not After = %s\n", cert.NotAfter.UTC().Format(time.RFC3339))
+		fmt.Fprintf(w, "Key Usage = %s\n", joined(cert.KeyUsage))
+		fmt.Fprintf(w, "Extended Key Usage = %s\n", joined(cert.ExtKeyUsage))
+		if cert.BasicConstraints == nil {
+			fmt.Fprintf(w, "Basic Constraints = (none)\n")
+		} else if cert.BasicConstraints.MaxPathLen != nil {
+			fmt.Fprintf(w, "Basic Constraints = CA=%t, MaxPathLen=%d\n", cert.BasicConstraints.CA, *cert.BasicConstraints.MaxPathLen)
+		} else {
+			fmt.Fprintf(w, "Basic Constraints = CA=%t\n", cert.BasicConstraints.CA)
+		}
+		fmt.Fprintf(w, "Policies = %s\n", joined(cert.Policies))
+		fmt.Fprintf(w, "OCSP Servers = %s\n", joined(cert.OCSPServers))
+		fmt.Fprintf(w, "Issuing Certificate URLs = %s\n", joined(cert.IssuingCertificateURLs))
+		fmt.Fprintf(w, "CRL Distribution Points = %s\n", joined(cert.CRLDistributionPoints))
+		for _, sct := range cert.SCTs {
+			fmt.Fprintf(w, "SCT = %s, log %s, %s\n", sct.VersionName(), sct.LogID, sct.Timestamp.Format(time.RFC3339))
+		}
+		for _, warning := range cert.Warnings {
+			fmt.Fprintf(w, "Warning = %s\n", warning)
+		}
+		io.WriteString(w, cert.PEM)
+	}
+	if len(report.ChainProblems) > 0 {
+		fmt.Fprintf(w, "\n")
+		for _, problem := range report.ChainProblems {
+			fmt.Fprintf(w, "Chain Problem = %s\n", problem)
+		}
+	}
+	if flight := report.ClientFlight; flight != nil {
+		fmt.Fprintf(w, "\n")
+		if flight.Encrypted {
+			fmt.Fprintf(w, "Client Flight = (encrypted in %s)\n", flight.Version)
+		} else {
+			fmt.Fprintf(w, "Client Flight = %s\n", strings.Join(flight.Sequence, ", "))
+		}
+		if flight.CertificateMessage != nil {
+			fmt.Fprintf(w, "Certificate Message = %d bytes\n", len(flight.CertificateMessage))
+		}
+		if verify := flight.CertificateVerify; verify != nil {
+			if verify.Algorithm != nil {
+				fmt.Fprintf(w, "CertificateVerify Algorithm = 0x%04x %s\n", *verify.Algorithm, verify.AlgorithmName)
+			}
+			fmt.Fprintf(w, "CertificateVerify Signature = %d bytes\n", len(verify.Signature))
+		}
+		for _, problem := range flight.Problems {
+			fmt.Fprintf(w, "Client Flight Problem = %s\n", problem)
+		}
+	}
+	if report.Verification != nil {
+		fmt.Fprintf(w, "\n")
+		if report.Verification.Verified {
+			fmt.Fprintf(w, "Verification = OK\n")
+		} else {
+			fmt.Fprintf(w, "Verification = Failed: %s\n", report.Verification.Error)
+		}
+		for _, chain := range report.Verification.Chains {
+			fmt.Fprintf(w, "Verified Chain = %s\n", strings.Join(chain, " -> "))
+		}
+	}
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "Client Auth = %s\n", report.Policy.ClientAuth)
+	fmt.Fprintf(w, "CA Hints = %s\n", joined(report.Policy.CAHints))
+	if len(report.Policy.SignatureAlgorithms) > 0 {
+		fmt.Fprintf(w, "Permitted Signature Algorithms = %s (TLS 1.2 and below only)\n", joined(report.Policy.SignatureAlgorithms))
+	}
+	for _, violation := range report.PolicyViolations {
+		fmt.Fprintf(w, "Policy Violation = %s\n", violation)
 	}
-	return f
 }
 
-func handleClientcert(w http.ResponseWriter, req *http.Request) {
-	if req.URL.Path != "/" {
+func handleClientcert(w http.ResponseWriter, req *http.Request, server *serverconfig.Server, policy *clientcertPolicy) {
+	if req.URL.Path != "/" || !server.EndpointEnabled(req.URL.Path) {
 		http.NotFound(w, req)
 		return
 	}
 
-	certs := req.TLS.PeerCertificates
+	var flight *tlshacks.ClientFlight
+	if conn, ok := req.Context().Value(tlshacks.ConnKey).(*tlshacks.Conn); ok {
+		flight = conn.ClientFlight()
+	}
+	report := makeCertReport(req.TLS.PeerCertificates, policy, flight, req.TLS.Version)
 
-	if verbose {
-		log.Printf("clientcert: %v", fingerprints(certs))
+	// The report is still sent when the request is rejected, so the client can see why
+	status := http.StatusOK
+	if len(report.PolicyViolations) > 0 {
+		status = http.StatusForbidden
 	}
 
-	w.Header().Set("Content-Type", "text/plain")
-	for _, cert := range certs {
-		fmt.Fprintf(w, "Subject = %s\n", cert.Subject.String())
-		fmt.Fprintf(w, "Issuer = %s\n", cert.Issuer.String())
-		fmt.Fprintf(w, "Certificate SHA-256 = %x\n", sha256.Sum256(cert.Raw))
-		fmt.Fprintf(w, "Public Key SHA-256 = %x\n", sha256.Sum256(cert.RawSubjectPublicKeyInfo))
-		pem.Encode(w, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	w.Header().Set("Vary", "Accept")
+	switch negotiate.Format(req, "text", "html", "json") {
+	case "json":
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "    ")
+		encoder.Encode(report)
+	case "html":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(status)
+		if err := templates.ExecuteTemplate(w, "clientcert.html", report); err != nil {
+			log.Printf("error executing template clientcert.html: %s", err)
+		}
+	default:
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(status)
+		writeCertReportText(w, report)
 	}
 }
 
-func runClientcert(config config) {
-	streamListener, err := listener.Open(config.listen)
+func runClientcert(server *serverconfig.Server, logger *slog.Logger) {
+	streamListener, err := listener.Open(server.Listen)
 	if err != nil {
 		log.Fatalf("error opening clientcert listener: %s", err)
 	}
 	defer streamListener.Close()
 
-	tlsConfig := &tls.Config{
-		NextProtos:             []string{"h2", "http/1.1", acme.ALPNProto},
-		SessionTicketsDisabled: true,
-		ClientAuth:             tls.RequireAnyClientCert,
-		GetCertificate:         parseCertString(config.cert),
-	}
-	httpServer := &http.Server{
-		ReadTimeout:  5 * time.Second,
-		WriteTimeout: 10 * time.Second,
-		IdleTimeout:  5 * time.Second,
-		Handler:      http.HandlerFunc(handleClientcert),
-	}
-	httpServer.SetKeepAlivesEnabled(false)
+	tlsConfig, err := server.TLSConfig()
+	if err != nil {
+		log.Fatalf("clientcert: %s", err)
+	}
+	tlsConfig.SessionTicketsDisabled = true
+	tlsConfig.GetCertificate = server.GetCertificate()
+
+	policy, err := newClientcertPolicy(server)
+	if err != nil {
+		log.Fatalf("clientcert: %s", err)
+	}
+	if err := policy.configureTLS(tlsConfig, server); err != nil {
+		log.Fatalf("clientcert: %s", err)
+	}
 
-	tlsListener := tls.NewListener(streamListener, tlsConfig)
+	httpServer := server.HTTPServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		handleClientcert(w, req, server, policy)
+	}))
+	httpServer.ConnContext = tlshacks.ConnContext
+	hooks := listenerMetrics.Hooks()
+	hooks.CaptureClientFlight = true
+	if logger != nil {
+		connLogger := connlog.New(logger)
+		httpServer.ConnState = connLogger.ConnState
+		hooks = tlshacks.CombineListenerHooks(hooks, connLogger.Hooks())
+	}
+	tlsListener := tls.NewListener(tlshacks.NewListenerWithHooks(streamListener, hooks), tlsConfig)
 	log.Fatal(httpServer.Serve(tlsListener))
 }
@@ -28,17 +28,36 @@ package main
 import (
 	"crypto/tls"
 	"encoding/json"
-	"golang.org/x/crypto/acme"
 	"log"
+	"log/slog"
 	"net/http"
-	"time"
 
 	"src.agwa.name/go-listener"
 	"src.agwa.name/tlshacks"
+	"src.agwa.name/tlshacks/internal/connlog"
+	"src.agwa.name/tlshacks/internal/serverconfig"
 )
 
-func handleClienthello(w http.ResponseWriter, req *http.Request) {
-	if req.URL.Path != "/" {
+// ticketInfo reports what the server made of the ticket offered by the client
+type ticketInfo struct {
+	Offered  bool `json:"offered"`
+	Decrypts bool `json:"decrypts"` // whether the ticket was issued by this server and decrypts successfully
+	Resumed  bool `json:"resumed"`
+}
+
+type clienthelloResponse struct {
+	*tlshacks.ClientHelloInfo
+	Ticket *ticketInfo `json:"ticket,omitempty"`
+}
+
+func handleClienthello(w http.ResponseWriter, req *http.Request, server *serverconfig.Server, tlsConfig *tls.Config) {
+	if !server.EndpointEnabled(req.URL.Path) {
+		http.NotFound(w, req)
+		return
+	} else if req.URL.Path == "/pha" {
+		handlePHA(w, req)
+		return
+	} else if req.URL.Path != "/" {
 		http.NotFound(w, req)
 		return
 	}
@@ -46,33 +65,52 @@ func handleClienthello(w http.ResponseWriter, req *http.Request) {
 	clientHello := req.Context().Value(tlshacks.ClientHelloKey).([]byte)
 	info := tlshacks.UnmarshalClientHello(clientHello)
 
+	// An unparseable ClientHello is reported as null
+	var response *clienthelloResponse
+	if info != nil {
+		response = &clienthelloResponse{ClientHelloInfo: info}
+		if !tlsConfig.SessionTicketsDisabled {
+			response.Ticket = &ticketInfo{Resumed: req.TLS.DidResume}
+			if ticket := info.SessionTicket(); ticket != nil {
+				response.Ticket.Offered = true
+				if session, err := tlsConfig.DecryptTicket(ticket, *req.TLS); err == nil && session != nil {
+					response.Ticket.Decrypts = true
+				}
+			}
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	encoder := json.NewEncoder(w)
 	encoder.SetIndent("", "    ")
-	encoder.Encode(info)
+	encoder.Encode(response)
 }
 
-func runClienthello(config config, enableResumption bool) {
-	streamListener, err := listener.Open(config.listen)
+func runClienthello(server *serverconfig.Server, logger *slog.Logger, enableResumption bool) {
+	streamListener, err := listener.Open(server.Listen)
 	if err != nil {
 		log.Fatalf("error opening clienthello listener: %s", err)
 	}
 	defer streamListener.Close()
 
-	tlsConfig := &tls.Config{
-		NextProtos:             []string{"h2", "http/1.1", acme.ALPNProto},
-		SessionTicketsDisabled: !enableResumption,
-		GetCertificate:         parseCertString(config.cert),
+	tlsConfig, err := server.TLSConfig()
+	if err != nil {
+		log.Fatalf("clienthello: %s", err)
 	}
-	httpServer := &http.Server{
-		ReadTimeout:  5 * time.Second,
-		WriteTimeout: 10 * time.Second,
-		IdleTimeout:  5 * time.Second,
-		Handler:      http.HandlerFunc(handleClienthello),
-		ConnContext:  tlshacks.ConnContext,
+	tlsConfig.SessionTicketsDisabled = !enableResumption
+	tlsConfig.GetCertificate = server.GetCertificate()
+
+	httpServer := server.HTTPServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		handleClienthello(w, req, server, tlsConfig)
+	}))
+	httpServer.ConnContext = tlshacks.ConnContext
+	hooks := listenerMetrics.Hooks()
+	if logger != nil {
+		connLogger := connlog.New(logger)
+		httpServer.ConnState = connLogger.ConnState
+		hooks = tlshacks.CombineListenerHooks(hooks, connLogger.Hooks())
 	}
-	httpServer.SetKeepAlivesEnabled(false)
 
-	tlsListener := tls.NewListener(tlshacks.NewListener(streamListener), tlsConfig)
+	tlsListener := tls.NewListener(tlshacks.NewListenerWithHooks(streamListener, hooks), tlsConfig)
 	log.Fatal(httpServer.Serve(tlsListener))
 }
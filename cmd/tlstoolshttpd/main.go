@@ -26,48 +26,95 @@
 package main
 
 import (
+	"errors"
 	"flag"
+	"log"
+	"log/slog"
+	"net/http"
+	"slices"
 	"strings"
 
-	"src.agwa.name/go-listener/cert"
+	"src.agwa.name/tlshacks/internal/metrics"
+	"src.agwa.name/tlshacks/internal/serverconfig"
 )
 
-var verbose = false
-
-func parseCertString(str string) cert.GetCertificateFunc {
-	if strings.HasPrefix(str, "/") {
-		// assume it's a path to a certificate
-		return cert.GetCertificateFromFile(str)
-	} else {
-		// assume it's a hostname
-		return cert.GetCertificateAutomatically([]string{str})
-	}
-}
-
-type config struct {
-	cert   string
-	listen string
-}
+var (
+	metricsRegistry = new(metrics.Registry)
+	listenerMetrics = metrics.NewListenerMetrics(metricsRegistry)
+)
 
 func main() {
-	var clientcertConfig, clienthelloConfig, clienthelloResumptionConfig config
-	flag.StringVar(&clientcertConfig.cert, "clientcert-cert", "", "Hostname or certificate file for clientcert")
-	flag.StringVar(&clientcertConfig.listen, "clientcert-listen", "", "Socket for clientcert to listen on")
-	flag.StringVar(&clienthelloConfig.cert, "clienthello-cert", "", "Hostname or certificate file for clienthello")
-	flag.StringVar(&clienthelloConfig.listen, "clienthello-listen", "", "Socket for clienthello to listen on")
-	flag.StringVar(&clienthelloResumptionConfig.cert, "clienthello-resumption-cert", "", "Hostname or certificate file for clienthello with resumption")
-	flag.StringVar(&clienthelloResumptionConfig.listen, "clienthello-resumption-listen", "", "Socket for clienthello with resumption to listen on")
-	flag.BoolVar(&verbose, "verbose", false, "Enable verbose logging")
+	flags := serverconfig.NewFlags(flag.CommandLine)
+	flags.ServerFlags(flag.CommandLine, "clientcert", "clientcert-", "clientcert")
+	flag.Func("clientcert-ca-bundle", "PEM file of CAs to verify client certificates against", func(value string) error {
+		flags.SetServer("clientcert", func(server *serverconfig.Server) { server.CABundle = value })
+		return nil
+	})
+	flag.Func("clientcert-client-auth", "Client certificate mode for clientcert: "+strings.Join(serverconfig.ClientAuthModes, ", ")+" (default require)", func(value string) error {
+		if !slices.Contains(serverconfig.ClientAuthModes, value) {
+			return errors.New("unknown mode")
+		}
+		flags.SetServer("clientcert", func(server *serverconfig.Server) { server.ClientAuth = value })
+		return nil
+	})
+	flag.Func("clientcert-ca-hints", "PEM file of CAs to list in clientcert's CertificateRequest (default empty list)", func(value string) error {
+		flags.SetServer("clientcert", func(server *serverconfig.Server) { server.CAHints = value })
+		return nil
+	})
+	flag.Func("clientcert-signature-algorithms", "Comma-separated list of signature algorithms which clientcert permits in CertificateVerify (enforced in TLS 1.2 and below only; empty means unrestricted)", func(value string) error {
+		algorithms := []string{}
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name == "" {
+				continue
+			}
+			if _, err := serverconfig.ParseSignatureAlgorithm(name); err != nil {
+				return err
+			}
+			algorithms = append(algorithms, name)
+		}
+		flags.SetServer("clientcert", func(server *serverconfig.Server) { server.SignatureAlgorithms = algorithms })
+		return nil
+	})
+	flags.ServerFlags(flag.CommandLine, "clienthello", "clienthello-", "clienthello")
+	flags.ServerFlags(flag.CommandLine, "clienthello-resumption", "clienthello-resumption-", "clienthello with resumption")
 	flag.Parse()
 
-	if clientcertConfig.listen != "" {
-		go runClientcert(clientcertConfig)
+	config, err := flags.Config()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := config.SetupLogging(); err != nil {
+		log.Fatal(err)
+	}
+
+	servers := map[string]func(*serverconfig.Server, *slog.Logger){
+		"clientcert":             runClientcert,
+		"clienthello":            func(server *serverconfig.Server, logger *slog.Logger) { runClienthello(server, logger, false) },
+		"clienthello-resumption": func(server *serverconfig.Server, logger *slog.Logger) { runClienthello(server, logger, true) },
+	}
+	// The configuration file can define additional clientcert servers (e.g. "clientcert-optional"),
+	// so that several client certificate modes can be tested at once
+	for name := range config.Servers {
+		if strings.HasPrefix(name, "clientcert-") {
+			servers[name] = runClientcert
+		}
+	}
+	for name := range servers {
+		if server := config.Server(name); server != nil {
+			if err := server.Validate(); err != nil {
+				log.Fatalf("%s: %s", name, err)
+			}
+		}
 	}
-	if clienthelloConfig.listen != "" {
-		go runClienthello(clienthelloConfig, false)
+	adminMux := http.NewServeMux()
+	adminMux.Handle("/metrics", metricsRegistry)
+	if err := config.ServeAdmin(adminMux); err != nil {
+		log.Fatal(err)
 	}
-	if clienthelloResumptionConfig.listen != "" {
-		go runClienthello(clienthelloResumptionConfig, true)
+	for name, run := range servers {
+		if server := config.Server(name); server != nil {
+			go run(server, config.Logger(name))
+		}
 	}
 	select {}
 }
@@ -0,0 +1,80 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package main
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"net/http"
+
+	"src.agwa.name/tlshacks"
+)
+
+// phaReport describes whether TLS 1.3 post-handshake client authentication (RFC 8446, Section 4.6.2)
+// would be permitted on the connection
+type phaReport struct {
+	Advertised bool     `json:"advertised"` // whether the ClientHello contained post_handshake_auth
+	Version    string   `json:"version"`
+	Protocol   string   `json:"protocol"`
+	Permitted  bool     `json:"permitted"` // whether a server may send a post-handshake CertificateRequest on this connection
+	Reasons    []string `json:"reasons"`   // why it's not permitted
+	Server     string   `json:"server"`    // what this server can do about it
+}
+
+// phaServerLimitation explains why this endpoint never actually attempts PHA
+const phaServerLimitation = "This server's TLS stack (Go crypto/tls) cannot send a post-handshake CertificateRequest, so PHA is never attempted, even when permitted"
+
+// handlePHA reports what the client advertised and whether post-handshake authentication
+// would be permitted on this connection.  It can't go further and attempt PHA, since Go's
+// crypto/tls doesn't implement sending a post-handshake CertificateRequest as a server
+// (nor does it let the application write handshake messages).
+func handlePHA(w http.ResponseWriter, req *http.Request) {
+	clientHello := req.Context().Value(tlshacks.ClientHelloKey).([]byte)
+	info := tlshacks.UnmarshalClientHello(clientHello)
+
+	report := &phaReport{
+		Advertised: info != nil && info.Info.PostHandshakeAuth,
+		Version:    tls.VersionName(req.TLS.Version),
+		Protocol:   req.TLS.NegotiatedProtocol,
+		Reasons:    []string{},
+		Server:     phaServerLimitation,
+	}
+	if !report.Advertised {
+		report.Reasons = append(report.Reasons, "The client did not send the post_handshake_auth extension, so a server must not send a post-handshake CertificateRequest (RFC 8446, Section 4.6.2)")
+	}
+	if req.TLS.Version != tls.VersionTLS13 {
+		report.Reasons = append(report.Reasons, "Post-handshake authentication requires TLS 1.3, but "+report.Version+" was negotiated")
+	}
+	if req.TLS.NegotiatedProtocol == "h2" {
+		report.Reasons = append(report.Reasons, "Post-handshake authentication is prohibited in HTTP/2 (RFC 8740); connect with HTTP/1.1 instead")
+	}
+	report.Permitted = len(report.Reasons) == 0
+
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "    ")
+	encoder.Encode(report)
+}
@@ -0,0 +1,178 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"src.agwa.name/tlshacks"
+	"src.agwa.name/tlshacks/internal/serverconfig"
+)
+
+// clientcertPolicy is how a clientcert server requests and checks client certificates.
+//
+// Note that crypto/tls doesn't let servers choose the signature algorithms listed in
+// the CertificateRequest, so SignatureAlgorithms can't be advertised to the client.
+// Instead, the CertificateVerify captured from the client's flight is checked after the
+// handshake, and requests from clients which used another algorithm are rejected.  This
+// is only possible in TLS 1.2 and below: TLS 1.3 encrypts the CertificateVerify, so
+// connections using TLS 1.3 can't be restricted.
+type clientcertPolicy struct {
+	ClientAuth          string   `json:"client_auth"`          // see serverconfig.ClientAuthModes
+	CAHints             []string `json:"ca_hints"`             // subjects listed in the CertificateRequest's certificate_authorities
+	SignatureAlgorithms []string `json:"signature_algorithms"` // permitted CertificateVerify algorithms; empty if unrestricted
+
+	roots      *x509.CertPool // nil if there's no CA bundle
+	algorithms []uint16
+}
+
+func loadCertificates(path string) ([]*x509.Certificate, error) {
+	pemBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, pemBytes = pem.Decode(pemBytes)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("%s: no certificates found", path)
+	}
+	return certs, nil
+}
+
+func makeCertPool(certs []*x509.Certificate) *x509.CertPool {
+	pool := x509.NewCertPool()
+	for _, cert := range certs {
+		pool.AddCert(cert)
+	}
+	return pool
+}
+
+func newClientcertPolicy(server *serverconfig.Server) (*clientcertPolicy, error) {
+	policy := &clientcertPolicy{
+		ClientAuth:          server.ClientAuth,
+		CAHints:             []string{},
+		SignatureAlgorithms: []string{},
+	}
+	if server.CABundle != "" {
+		certs, err := loadCertificates(server.CABundle)
+		if err != nil {
+			return nil, err
+		}
+		policy.roots = makeCertPool(certs)
+	}
+	for _, name := range server.SignatureAlgorithms {
+		algorithm, err := serverconfig.ParseSignatureAlgorithm(name)
+		if err != nil {
+			return nil, err
+		}
+		policy.algorithms = append(policy.algorithms, algorithm)
+		policy.SignatureAlgorithms = append(policy.SignatureAlgorithms, name)
+	}
+	return policy, nil
+}
+
+// configureTLS sets up tlsConfig to request client certificates according to the policy
+// and the server's CA hints
+func (policy *clientcertPolicy) configureTLS(tlsConfig *tls.Config, server *serverconfig.Server) error {
+	switch policy.ClientAuth {
+	case "request", "verify_if_given":
+		tlsConfig.ClientAuth = tls.RequestClientCert
+	case "require", "verify":
+		tlsConfig.ClientAuth = tls.RequireAnyClientCert
+	default:
+		return fmt.Errorf("unknown client_auth mode %q", policy.ClientAuth)
+	}
+
+	// crypto/tls sends the subjects of ClientCAs in the CertificateRequest, and only uses ClientCAs
+	// for verification with tls.VerifyClientCertIfGiven and tls.RequireAndVerifyClientCert.
+	// Since those modes aren't used, the hints are independent of the CA bundle used for
+	// verification, and an empty list is sent if there are no hints.
+	if server.CAHints != "" {
+		certs, err := loadCertificates(server.CAHints)
+		if err != nil {
+			return err
+		}
+		tlsConfig.ClientCAs = makeCertPool(certs)
+		for _, cert := range certs {
+			policy.CAHints = append(policy.CAHints, cert.Subject.String())
+		}
+	}
+
+	if policy.ClientAuth == "verify" || policy.ClientAuth == "verify_if_given" {
+		tlsConfig.VerifyPeerCertificate = policy.verifyPeerCertificate
+	}
+	return nil
+}
+
+func (policy *clientcertPolicy) verifyPeerCertificate(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	if len(rawCerts) == 0 {
+		return nil // RequireAnyClientCert has already rejected this if necessary
+	}
+	certs := make([]*x509.Certificate, len(rawCerts))
+	for i, rawCert := range rawCerts {
+		cert, err := x509.ParseCertificate(rawCert)
+		if err != nil {
+			return err
+		}
+		certs[i] = cert
+	}
+	if result := verifyChain(certs, policy.roots, time.Now()); !result.Verified {
+		return errors.New(result.Error)
+	}
+	return nil
+}
+
+// algorithmPermitted reports whether the CertificateVerify's algorithm is permitted,
+// or returns nil if there's no restriction or it can't be determined (in TLS 1.1 and
+// below, the CertificateVerify doesn't specify an algorithm)
+func (policy *clientcertPolicy) algorithmPermitted(verify *tlshacks.CertificateVerify) *bool {
+	if len(policy.algorithms) == 0 || verify == nil || verify.Algorithm == nil {
+		return nil
+	}
+	permitted := slices.Contains(policy.algorithms, *verify.Algorithm)
+	return &permitted
+}
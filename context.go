@@ -35,6 +35,9 @@ type contextKeyType int
 
 var ClientHelloKey = contextKeyType(0)
 
+// ConnKey is the context key for the *Conn, which can be used to get the ClientFlight
+var ConnKey = contextKeyType(1)
+
 func ConnContext(ctx context.Context, conn net.Conn) context.Context {
 	tlsConn, ok := conn.(*tls.Conn)
 	if !ok {
@@ -44,5 +47,6 @@ func ConnContext(ctx context.Context, conn net.Conn) context.Context {
 	if !ok {
 		return ctx
 	}
+	ctx = context.WithValue(ctx, ConnKey, tlshelloConn)
 	return context.WithValue(ctx, ClientHelloKey, tlshelloConn.ClientHello)
 }
@@ -0,0 +1,293 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package tlshacks
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ListDiff describes how a list in one ClientHello differs from the corresponding list in another
+type ListDiff[T comparable] struct {
+	Added     []T  `json:"added"`
+	Removed   []T  `json:"removed"`
+	Reordered bool `json:"reordered"` // true if the elements common to both lists appear in a different order
+}
+
+func (d *ListDiff[T]) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && !d.Reordered
+}
+
+func diffLists[T comparable](a, b []T) ListDiff[T] {
+	d := ListDiff[T]{Added: []T{}, Removed: []T{}}
+	var commonA, commonB []T
+	for _, elem := range a {
+		if slices.Contains(b, elem) {
+			commonA = append(commonA, elem)
+		} else {
+			d.Removed = append(d.Removed, elem)
+		}
+	}
+	for _, elem := range b {
+		if slices.Contains(a, elem) {
+			commonB = append(commonB, elem)
+		} else {
+			d.Added = append(d.Added, elem)
+		}
+	}
+	d.Reordered = !slices.Equal(commonA, commonB)
+	return d
+}
+
+// ExtensionChange describes an extension whose data differs between two ClientHellos
+type ExtensionChange struct {
+	Type uint16        `json:"type"`
+	Name string        `json:"name,omitempty"`
+	Old  ExtensionData `json:"old"`
+	New  ExtensionData `json:"new"`
+}
+
+// ClientHelloDiff describes the differences between two ClientHellos.  GREASE
+// values, randoms, session IDs, and key exchange values are ignored.
+type ClientHelloDiff struct {
+	OldVersion          ProtocolVersion             `json:"old_version"`
+	NewVersion          ProtocolVersion             `json:"new_version"`
+	SupportedVersions   ListDiff[ProtocolVersion]   `json:"supported_versions"`
+	CipherSuites        ListDiff[uint16]            `json:"cipher_suites"`
+	CompressionMethods  ListDiff[CompressionMethod] `json:"compression_methods"`
+	Extensions          ListDiff[uint16]            `json:"extensions"`
+	Groups              ListDiff[uint16]            `json:"groups"`
+	KeyShareGroups      ListDiff[uint16]            `json:"key_share_groups"`
+	SignatureAlgorithms ListDiff[uint16]            `json:"signature_algorithms"`
+	ALPN                ListDiff[string]            `json:"alpn"`
+	ExtensionData       []ExtensionChange           `json:"extension_data"`
+}
+
+// Extensions whose data is either random, or already covered by one of the lists in ClientHelloDiff
+var extensionsExcludedFromDiff = map[uint16]bool{
+	10:     true, // supported_groups
+	13:     true, // signature_algorithms
+	16:     true, // application_layer_protocol_negotiation
+	21:     true, // padding
+	35:     true, // session_ticket
+	41:     true, // pre_shared_key
+	43:     true, // supported_versions
+	51:     true, // key_share
+	0xfe0d: true, // encrypted_client_hello
+}
+
+func nonGrease[T any](list []T, code func(T) uint16) []T {
+	return slices.DeleteFunc(slices.Clone(list), func(elem T) bool { return isGreaseValue(code(elem)) })
+}
+
+func codeOf[T ~uint16](v T) uint16 { return uint16(v) }
+
+func extensionList[T any](hello *ClientHelloInfo, extType uint16, list func(ExtensionData) ([]T, bool)) []T {
+	if ext := hello.Extension(extType); ext != nil {
+		if elems, ok := list(ext.Data); ok {
+			return elems
+		}
+	}
+	return nil
+}
+
+func supportedVersionsOf(hello *ClientHelloInfo) []ProtocolVersion {
+	return extensionList(hello, 43, func(data ExtensionData) ([]ProtocolVersion, bool) {
+		d, ok := data.(*SupportedVersionsData)
+		if !ok {
+			return nil, false
+		}
+		return nonGrease(d.Versions, codeOf), true
+	})
+}
+
+func groupsOf(hello *ClientHelloInfo) []uint16 {
+	return extensionList(hello, 10, func(data ExtensionData) ([]uint16, bool) {
+		d, ok := data.(*SupportedGroupsData)
+		if !ok {
+			return nil, false
+		}
+		return nonGrease(d.Groups, codeOf), true
+	})
+}
+
+func keyShareGroupsOf(hello *ClientHelloInfo) []uint16 {
+	return extensionList(hello, 51, func(data ExtensionData) ([]uint16, bool) {
+		d, ok := data.(*KeyShareData)
+		if !ok {
+			return nil, false
+		}
+		var groups []uint16
+		for _, share := range d.Shares {
+			if !isGreaseValue(share.Group) {
+				groups = append(groups, share.Group)
+			}
+		}
+		return groups, true
+	})
+}
+
+func signatureAlgorithmsOf(hello *ClientHelloInfo) []uint16 {
+	return extensionList(hello, 13, func(data ExtensionData) ([]uint16, bool) {
+		d, ok := data.(*SignatureAlgorithmsData)
+		if !ok {
+			return nil, false
+		}
+		return nonGrease(d.Algorithms, codeOf), true
+	})
+}
+
+func alpnOf(hello *ClientHelloInfo) []string {
+	return extensionList(hello, 16, func(data ExtensionData) ([]string, bool) {
+		d, ok := data.(*ALPNData)
+		if !ok {
+			return nil, false
+		}
+		return d.Protocols, true
+	})
+}
+
+func cipherSuitesOf(hello *ClientHelloInfo) []uint16 {
+	var suites []uint16
+	for _, suite := range hello.CipherSuites {
+		if !suite.Grease {
+			suites = append(suites, suite.CodeUint16())
+		}
+	}
+	return suites
+}
+
+func extensionTypesOf(hello *ClientHelloInfo) []uint16 {
+	var types []uint16
+	for _, ext := range hello.Extensions {
+		if !ext.Grease {
+			types = append(types, ext.Type)
+		}
+	}
+	return types
+}
+
+// Diff returns the differences between ClientHellos a and b
+func Diff(a, b *ClientHelloInfo) *ClientHelloDiff {
+	d := &ClientHelloDiff{
+		OldVersion:          a.Version,
+		NewVersion:          b.Version,
+		SupportedVersions:   diffLists(supportedVersionsOf(a), supportedVersionsOf(b)),
+		CipherSuites:        diffLists(cipherSuitesOf(a), cipherSuitesOf(b)),
+		CompressionMethods:  diffLists(a.CompressionMethods, b.CompressionMethods),
+		Extensions:          diffLists(extensionTypesOf(a), extensionTypesOf(b)),
+		Groups:              diffLists(groupsOf(a), groupsOf(b)),
+		KeyShareGroups:      diffLists(keyShareGroupsOf(a), keyShareGroupsOf(b)),
+		SignatureAlgorithms: diffLists(signatureAlgorithmsOf(a), signatureAlgorithmsOf(b)),
+		ALPN:                diffLists(alpnOf(a), alpnOf(b)),
+		ExtensionData:       []ExtensionChange{},
+	}
+	for _, extA := range a.Extensions {
+		if extA.Grease || extensionsExcludedFromDiff[extA.Type] {
+			continue
+		}
+		extB := b.Extension(extA.Type)
+		if extB == nil || bytes.Equal(extensionRaw(extA.Data), extensionRaw(extB.Data)) {
+			continue
+		}
+		d.ExtensionData = append(d.ExtensionData, ExtensionChange{
+			Type: extA.Type,
+			Name: extA.Name,
+			Old:  extA.Data,
+			New:  extB.Data,
+		})
+	}
+	return d
+}
+
+// Empty returns true if there are no differences
+func (d *ClientHelloDiff) Empty() bool {
+	return d.OldVersion == d.NewVersion &&
+		d.SupportedVersions.Empty() &&
+		d.CipherSuites.Empty() &&
+		d.CompressionMethods.Empty() &&
+		d.Extensions.Empty() &&
+		d.Groups.Empty() &&
+		d.KeyShareGroups.Empty() &&
+		d.SignatureAlgorithms.Empty() &&
+		d.ALPN.Empty() &&
+		len(d.ExtensionData) == 0
+}
+
+func writeListDiff[T comparable](b *strings.Builder, label string, d *ListDiff[T], format func(T) string) {
+	if d.Empty() {
+		return
+	}
+	fmt.Fprintf(b, "%s:\n", label)
+	for _, elem := range d.Removed {
+		fmt.Fprintf(b, "    - %s\n", format(elem))
+	}
+	for _, elem := range d.Added {
+		fmt.Fprintf(b, "    + %s\n", format(elem))
+	}
+	if d.Reordered {
+		fmt.Fprintf(b, "    (reordered)\n")
+	}
+}
+
+func formatCode(name string) func(uint16) string {
+	return func(code uint16) string {
+		return strings.TrimSpace(fmt.Sprintf("0x%04x %s", code, name))
+	}
+}
+
+// String renders the differences as human-readable text
+func (d *ClientHelloDiff) String() string {
+	if d.Empty() {
+		return "No differences\n"
+	}
+	b := new(strings.Builder)
+	if d.OldVersion != d.NewVersion {
+		fmt.Fprintf(b, "legacy_version: %s -> %s\n", d.OldVersion, d.NewVersion)
+	}
+	writeListDiff(b, "supported_versions", &d.SupportedVersions, ProtocolVersion.String)
+	writeListDiff(b, "cipher_suites", &d.CipherSuites, func(code uint16) string {
+		return formatCode(CipherSuites[code].Name)(code)
+	})
+	writeListDiff(b, "compression_methods", &d.CompressionMethods, func(method CompressionMethod) string {
+		return fmt.Sprint(uint8(method))
+	})
+	writeListDiff(b, "extensions", &d.Extensions, func(code uint16) string {
+		return formatCode(ExtensionName(code))(code)
+	})
+	writeListDiff(b, "supported_groups", &d.Groups, formatCode(""))
+	writeListDiff(b, "key_share", &d.KeyShareGroups, formatCode(""))
+	writeListDiff(b, "signature_algorithms", &d.SignatureAlgorithms, formatCode(""))
+	writeListDiff(b, "alpn", &d.ALPN, func(protocol string) string { return protocol })
+	for _, change := range d.ExtensionData {
+		fmt.Fprintf(b, "extension 0x%04x %s changed:\n", change.Type, change.Name)
+		fmt.Fprintf(b, "    - %x\n", extensionRaw(change.Old))
+		fmt.Fprintf(b, "    + %x\n", extensionRaw(change.New))
+	}
+	return b.String()
+}
@@ -0,0 +1,126 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package tlshacks
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDiffLists(t *testing.T) {
+	tests := []struct {
+		a, b      []uint16
+		added     []uint16
+		removed   []uint16
+		reordered bool
+	}{
+		{nil, nil, nil, nil, false},
+		{[]uint16{1, 2, 3}, []uint16{1, 2, 3}, nil, nil, false},
+		{[]uint16{1, 2, 3}, []uint16{1, 2, 3, 4}, []uint16{4}, nil, false},
+		{[]uint16{1, 2, 3}, []uint16{1, 3}, nil, []uint16{2}, false},
+		{[]uint16{1, 2, 3}, []uint16{3, 2, 1}, nil, nil, true},
+		{[]uint16{1, 2, 3}, []uint16{4, 3, 1}, []uint16{4}, []uint16{2}, true},
+		{[]uint16{1, 2, 3}, []uint16{4, 1, 3}, []uint16{4}, []uint16{2}, false}, // additions and removals alone aren't reordering
+		{nil, []uint16{1}, []uint16{1}, nil, false},
+	}
+	for _, test := range tests {
+		d := diffLists(test.a, test.b)
+		if !slices.Equal(d.Added, test.added) || !slices.Equal(d.Removed, test.removed) || d.Reordered != test.reordered {
+			t.Errorf("diffLists(%v, %v) = %+v, expected added %v, removed %v, reordered %t", test.a, test.b, d, test.added, test.removed, test.reordered)
+		}
+		if empty := len(test.added) == 0 && len(test.removed) == 0 && !test.reordered; d.Empty() != empty {
+			t.Errorf("diffLists(%v, %v).Empty() = %t, expected %t", test.a, test.b, d.Empty(), empty)
+		}
+	}
+}
+
+// TestDiffIgnoresGreaseAndRandom diffs two ClientHellos from the same builder, which
+// differ in their random, session ID, GREASE values, and key shares
+func TestDiffIgnoresGreaseAndRandom(t *testing.T) {
+	builder := lintBuilder()
+	builder.Grease = true
+	var hellos [2]*ClientHelloInfo
+	for i := range hellos {
+		builder.SessionID = randomBytes(32)
+		info, err := builder.Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		hellos[i] = info
+	}
+	d := Diff(hellos[0], hellos[1])
+	if !d.Empty() {
+		t.Errorf("ClientHellos from the same builder differ:\n%s", d)
+	}
+	if s := d.String(); s != "No differences\n" {
+		t.Errorf("String() of empty diff is %q", s)
+	}
+}
+
+func TestDiffExtensionData(t *testing.T) {
+	a, err := lintBuilder().Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := lintBuilder().replaceExtension(ServerNameExtension("example.org")).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := Diff(a, b)
+	if len(d.ExtensionData) != 1 || d.ExtensionData[0].Type != 0 {
+		t.Fatalf("changed extensions are %+v, expected only server_name", d.ExtensionData)
+	}
+	expected := "extension 0x0000 server_name changed:\n" +
+		"    - 000e00000b6578616d706c652e636f6d\n" +
+		"    + 000e00000b6578616d706c652e6f7267\n"
+	if s := d.String(); s != expected {
+		t.Errorf("String() is:\n%s\nexpected:\n%s", s, expected)
+	}
+}
+
+func TestDiffBrowsers(t *testing.T) {
+	d := Diff(readTestHello(t, "chrome-131.hex"), readTestHello(t, "chrome-133.hex"))
+	if d.Empty() {
+		t.Fatal("Chrome 131 and 133 ClientHellos don't differ")
+	}
+	if !slices.Equal(d.Extensions.Removed, []uint16{17513}) || !slices.Equal(d.Extensions.Added, []uint16{17613}) {
+		t.Errorf("extensions removed %v and added %v, expected the application_settings codepoint to change", d.Extensions.Removed, d.Extensions.Added)
+	}
+	// Chrome permutes its extensions, so their order is expected to differ
+	if !d.Extensions.Reordered {
+		t.Error("extensions are not reordered")
+	}
+	if !d.CipherSuites.Empty() || !d.Groups.Empty() || !d.SignatureAlgorithms.Empty() || !d.KeyShareGroups.Empty() || len(d.ExtensionData) != 0 {
+		t.Errorf("unexpected differences:\n%s", d)
+	}
+	expected := "extensions:\n" +
+		"    - 0x4469 application_settings_old\n" +
+		"    + 0x44cd application_settings\n" +
+		"    (reordered)\n"
+	if s := d.String(); s != expected {
+		t.Errorf("String() is:\n%s\nexpected:\n%s", s, expected)
+	}
+}
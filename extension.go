@@ -26,7 +26,10 @@
 package tlshacks
 
 import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
 	"golang.org/x/crypto/cryptobyte"
+	"reflect"
 )
 
 type ExtensionData interface{}
@@ -39,6 +42,59 @@ type Extension struct {
 	Data    ExtensionData `json:"data"`
 }
 
+// extensionValid returns the value of the data's Valid field, or
+// true if the data doesn't have a Valid field (e.g. UnknownExtensionData).
+// Valid is false only if the data can't be parsed or violates its RFC; data
+// which is well-formed but uses a value the parser doesn't understand is valid.
+func extensionValid(data ExtensionData) bool {
+	value := reflect.ValueOf(data)
+	if value.Kind() == reflect.Pointer {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return true
+	}
+	if field := value.FieldByName("Valid"); field.Kind() == reflect.Bool {
+		return field.Bool()
+	}
+	return true
+}
+
+// Valid reports whether the extension's data was parsed successfully.  Extensions
+// without a parser are always valid.
+func (ext *Extension) Valid() bool {
+	return extensionValid(ext.Data)
+}
+
+// extensionRaw returns the value of the data's Raw field, or nil if the data doesn't have one
+func extensionRaw(data ExtensionData) []byte {
+	value := reflect.ValueOf(data)
+	if value.Kind() == reflect.Pointer {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+	if field := value.FieldByName("Raw"); field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.Uint8 {
+		return field.Bytes()
+	}
+	return nil
+}
+
+// unregisteredExtensionNames contains extensions which are widely sent but not registered with IANA
+var unregisteredExtensionNames = map[uint16]string{
+	17513: "application_settings_old",
+	17613: "application_settings",
+}
+
+// ExtensionName returns the name of the given extension type, or the empty string if it's unknown
+func ExtensionName(extType uint16) string {
+	if name, ok := unregisteredExtensionNames[extType]; ok {
+		return name
+	}
+	return Extensions[extType].Name
+}
+
 type UnknownExtensionData struct {
 	Raw []byte `json:"raw"`
 }
@@ -128,7 +184,6 @@ func ParseALPNData(raw []byte) ExtensionData {
 }
 
 // RFC 8422
-// TODO: provide info about groups beyond just the code (name, grease status, etc.)
 type SupportedGroupsData struct {
 	Raw    []byte   `json:"raw"`
 	Valid  bool     `json:"valid"`
@@ -184,13 +239,709 @@ func ParseECPointFormatsData(rawData []byte) ExtensionData {
 	return parsedData
 }
 
-var extensionParsers = map[uint16]func([]byte) ExtensionData{
+// RFC 8446, Section 4.2.3
+type SignatureAlgorithmsData struct {
+	Raw        []byte   `json:"raw"`
+	Valid      bool     `json:"valid"`
+	Algorithms []uint16 `json:"algorithms"`
+}
+
+func ParseSignatureAlgorithmsData(rawData []byte) ExtensionData {
+	parsedData := &SignatureAlgorithmsData{Raw: rawData, Algorithms: []uint16{}}
+	data := cryptobyte.String(rawData)
+	var list cryptobyte.String
+	if !data.ReadUint16LengthPrefixed(&list) || list.Empty() {
+		return parsedData
+	}
+	for !list.Empty() {
+		var code uint16
+		if !list.ReadUint16(&code) {
+			return parsedData
+		}
+		parsedData.Algorithms = append(parsedData.Algorithms, code)
+	}
+	if !data.Empty() {
+		return parsedData
+	}
+	parsedData.Valid = true
+	return parsedData
+}
+
+// RFC 8446, Section 4.2.1
+type SupportedVersionsData struct {
+	Raw      []byte            `json:"raw"`
+	Valid    bool              `json:"valid"`
+	Versions []ProtocolVersion `json:"versions"`
+}
+
+func ParseSupportedVersionsData(rawData []byte) ExtensionData {
+	parsedData := &SupportedVersionsData{Raw: rawData, Versions: []ProtocolVersion{}}
+	data := cryptobyte.String(rawData)
+	var list cryptobyte.String
+	if !data.ReadUint8LengthPrefixed(&list) || list.Empty() {
+		return parsedData
+	}
+	for !list.Empty() {
+		var version uint16
+		if !list.ReadUint16(&version) {
+			return parsedData
+		}
+		parsedData.Versions = append(parsedData.Versions, ProtocolVersion(version))
+	}
+	if !data.Empty() {
+		return parsedData
+	}
+	parsedData.Valid = true
+	return parsedData
+}
+
+// RFC 8446, Section 4.2.8
+type KeyShareData struct {
+	Raw    []byte          `json:"raw"`
+	Valid  bool            `json:"valid"`
+	Shares []KeyShareEntry `json:"shares"`
+}
+
+type KeyShareEntry struct {
+	Group       uint16 `json:"group"`
+	KeyExchange []byte `json:"key_exchange"`
+}
+
+func ParseKeyShareData(rawData []byte) ExtensionData {
+	parsedData := &KeyShareData{Raw: rawData, Shares: []KeyShareEntry{}}
+	data := cryptobyte.String(rawData)
+	var list cryptobyte.String
+	if !data.ReadUint16LengthPrefixed(&list) {
+		return parsedData
+	}
+	for !list.Empty() {
+		var entry KeyShareEntry
+		if !list.ReadUint16(&entry.Group) || !list.ReadUint16LengthPrefixed((*cryptobyte.String)(&entry.KeyExchange)) || len(entry.KeyExchange) == 0 {
+			return parsedData
+		}
+		parsedData.Shares = append(parsedData.Shares, entry)
+	}
+	if !data.Empty() {
+		return parsedData
+	}
+	parsedData.Valid = true
+	return parsedData
+}
+
+// OCSPStatusRequest - RFC 6066, Section 8
+type OCSPStatusRequest struct {
+	ResponderIDs      [][]byte           `json:"responder_ids"`
+	RequestExtensions []RequestExtension `json:"request_extensions"`
+}
+
+// RequestExtension is an X.509 extension included in an OCSP request (RFC 6960, Section 4.4)
+type RequestExtension struct {
+	ID       string `json:"id"`
+	Critical bool   `json:"critical"`
+	Value    []byte `json:"value"`
+}
+
+func readOCSPStatusRequest(s *cryptobyte.String) *OCSPStatusRequest {
+	request := &OCSPStatusRequest{ResponderIDs: [][]byte{}, RequestExtensions: []RequestExtension{}}
+	var responderIDList, extensions cryptobyte.String
+	if !s.ReadUint16LengthPrefixed(&responderIDList) {
+		return nil
+	}
+	for !responderIDList.Empty() {
+		var responderID cryptobyte.String
+		if !responderIDList.ReadUint16LengthPrefixed(&responderID) || responderID.Empty() {
+			return nil
+		}
+		request.ResponderIDs = append(request.ResponderIDs, responderID)
+	}
+	if !s.ReadUint16LengthPrefixed(&extensions) {
+		return nil
+	}
+	if !extensions.Empty() {
+		var pkixExtensions []pkix.Extension
+		if rest, err := asn1.Unmarshal(extensions, &pkixExtensions); err != nil || len(rest) != 0 {
+			return nil
+		}
+		for _, ext := range pkixExtensions {
+			request.RequestExtensions = append(request.RequestExtensions, RequestExtension{
+				ID:       ext.Id.String(),
+				Critical: ext.Critical,
+				Value:    ext.Value,
+			})
+		}
+	}
+	return request
+}
+
+// status_request - RFC 6066, Section 8
+//
+// CertificateStatusType is an extension point, so a status type other than ocsp is
+// not malformed: its request is kept as opaque bytes in Request.
+type StatusRequestData struct {
+	Raw        []byte             `json:"raw"`
+	Valid      bool               `json:"valid"`
+	StatusType uint8              `json:"status_type"`
+	OCSP       *OCSPStatusRequest `json:"ocsp"`    // non-nil if StatusType is 1 (ocsp)
+	Request    []byte             `json:"request"` // non-nil if StatusType is unknown
+}
+
+func ParseStatusRequestData(rawData []byte) ExtensionData {
+	parsedData := &StatusRequestData{Raw: rawData}
+	data := cryptobyte.String(rawData)
+	if !data.ReadUint8(&parsedData.StatusType) {
+		return parsedData
+	}
+	switch parsedData.StatusType {
+	case 1:
+		parsedData.OCSP = readOCSPStatusRequest(&data)
+		if parsedData.OCSP == nil {
+			return parsedData
+		}
+	default:
+		// The format of other status types is unknown, so the rest of the data is their request
+		data.ReadBytes(&parsedData.Request, len(data))
+	}
+	if !data.Empty() {
+		return parsedData
+	}
+	parsedData.Valid = true
+	return parsedData
+}
+
+// status_request_v2 - RFC 6961, Section 2.2
+type StatusRequestV2Data struct {
+	Raw      []byte                `json:"raw"`
+	Valid    bool                  `json:"valid"`
+	Requests []StatusRequestV2Item `json:"requests"`
+}
+
+type StatusRequestV2Item struct {
+	StatusType uint8              `json:"status_type"` // 1 = ocsp, 2 = ocsp_multi
+	OCSP       *OCSPStatusRequest `json:"ocsp"`        // nil if StatusType is unknown
+	Request    []byte             `json:"request"`
+}
+
+func ParseStatusRequestV2Data(rawData []byte) ExtensionData {
+	parsedData := &StatusRequestV2Data{Raw: rawData, Requests: []StatusRequestV2Item{}}
+	data := cryptobyte.String(rawData)
+	var list cryptobyte.String
+	if !data.ReadUint16LengthPrefixed(&list) || list.Empty() {
+		return parsedData
+	}
+	for !list.Empty() {
+		var item StatusRequestV2Item
+		if !list.ReadUint8(&item.StatusType) || !list.ReadUint16LengthPrefixed((*cryptobyte.String)(&item.Request)) {
+			return parsedData
+		}
+		if item.StatusType == 1 || item.StatusType == 2 {
+			request := cryptobyte.String(item.Request)
+			if item.OCSP = readOCSPStatusRequest(&request); item.OCSP == nil || !request.Empty() {
+				return parsedData
+			}
+		}
+		parsedData.Requests = append(parsedData.Requests, item)
+	}
+	if !data.Empty() {
+		return parsedData
+	}
+	parsedData.Valid = true
+	return parsedData
+}
+
+// DistinguishedName is a DER-encoded X.501 Name, along with its RFC 4514 string
+// representation.  String is empty if the name can't be decoded.
+type DistinguishedName struct {
+	Raw    []byte `json:"raw"`
+	String string `json:"string"`
+}
+
+func makeDistinguishedName(raw []byte) (DistinguishedName, bool) {
+	var rdns pkix.RDNSequence
+	if rest, err := asn1.Unmarshal(raw, &rdns); err != nil || len(rest) != 0 {
+		return DistinguishedName{Raw: raw}, false
+	}
+	return DistinguishedName{Raw: raw, String: rdns.String()}, true
+}
+
+// certificate_authorities - RFC 8446, Section 4.2.4
+type CertificateAuthoritiesData struct {
+	Raw         []byte              `json:"raw"`
+	Valid       bool                `json:"valid"`
+	Authorities []DistinguishedName `json:"authorities"`
+}
+
+func ParseCertificateAuthoritiesData(rawData []byte) ExtensionData {
+	parsedData := &CertificateAuthoritiesData{Raw: rawData, Authorities: []DistinguishedName{}}
+	data := cryptobyte.String(rawData)
+	var list cryptobyte.String
+	if !data.ReadUint16LengthPrefixed(&list) || list.Empty() {
+		return parsedData
+	}
+	for !list.Empty() {
+		var raw cryptobyte.String
+		if !list.ReadUint16LengthPrefixed(&raw) || raw.Empty() {
+			return parsedData
+		}
+		name, ok := makeDistinguishedName(raw)
+		parsedData.Authorities = append(parsedData.Authorities, name)
+		if !ok {
+			return parsedData
+		}
+	}
+	if !data.Empty() {
+		return parsedData
+	}
+	parsedData.Valid = true
+	return parsedData
+}
+
+// Certificate types from https://www.iana.org/assignments/tls-extensiontype-values/tls-extensiontype-values.xhtml#tls-extensiontype-values-3
+var CertificateTypes = map[uint8]string{
+	0: "X509",
+	1: "OpenPGP",
+	2: "RawPublicKey",
+	3: "1609Dot2",
+}
+
+type CertificateType struct {
+	Code uint8  `json:"code"`
+	Name string `json:"name,omitempty"`
+}
+
+// client_certificate_type and server_certificate_type - RFC 7250, Section 3
+type CertificateTypeData struct {
+	Raw   []byte            `json:"raw"`
+	Valid bool              `json:"valid"`
+	Types []CertificateType `json:"types"`
+}
+
+func ParseCertificateTypeData(rawData []byte) ExtensionData {
+	parsedData := &CertificateTypeData{Raw: rawData, Types: []CertificateType{}}
+	data := cryptobyte.String(rawData)
+	var list cryptobyte.String
+	if !data.ReadUint8LengthPrefixed(&list) || list.Empty() {
+		return parsedData
+	}
+	for !list.Empty() {
+		var code uint8
+		if !list.ReadUint8(&code) {
+			return parsedData
+		}
+		parsedData.Types = append(parsedData.Types, CertificateType{Code: code, Name: CertificateTypes[code]})
+	}
+	if !data.Empty() {
+		return parsedData
+	}
+	parsedData.Valid = true
+	return parsedData
+}
+
+// trusted_ca_keys - RFC 6066, Section 6
+type TrustedCAKeysData struct {
+	Raw         []byte             `json:"raw"`
+	Valid       bool               `json:"valid"`
+	Authorities []TrustedAuthority `json:"authorities"`
+}
+
+type TrustedAuthority struct {
+	IdentifierType    uint8              `json:"identifier_type"` // 0 = pre_agreed, 1 = key_sha1_hash, 2 = x509_name, 3 = cert_sha1_hash
+	SHA1Hash          []byte             `json:"sha1_hash,omitempty"`
+	DistinguishedName *DistinguishedName `json:"distinguished_name,omitempty"`
+}
+
+func ParseTrustedCAKeysData(rawData []byte) ExtensionData {
+	parsedData := &TrustedCAKeysData{Raw: rawData, Authorities: []TrustedAuthority{}}
+	data := cryptobyte.String(rawData)
+	var list cryptobyte.String
+	if !data.ReadUint16LengthPrefixed(&list) {
+		return parsedData
+	}
+	for !list.Empty() {
+		var authority TrustedAuthority
+		if !list.ReadUint8(&authority.IdentifierType) {
+			return parsedData
+		}
+		switch authority.IdentifierType {
+		case 0:
+		case 1, 3:
+			if !list.ReadBytes(&authority.SHA1Hash, 20) {
+				return parsedData
+			}
+		case 2:
+			var raw cryptobyte.String
+			if !list.ReadUint16LengthPrefixed(&raw) || raw.Empty() {
+				return parsedData
+			}
+			name, ok := makeDistinguishedName(raw)
+			authority.DistinguishedName = &name
+			if !ok {
+				return parsedData
+			}
+		default:
+			return parsedData
+		}
+		parsedData.Authorities = append(parsedData.Authorities, authority)
+	}
+	if !data.Empty() {
+		return parsedData
+	}
+	parsedData.Valid = true
+	return parsedData
+}
+
+// session_ticket - RFC 5077, Section 3.2
+type SessionTicketData struct {
+	Raw    []byte `json:"raw"`
+	Valid  bool   `json:"valid"`
+	Ticket []byte `json:"ticket"` // empty if the client is requesting a new ticket
+}
+
+func ParseSessionTicketData(rawData []byte) ExtensionData {
+	return &SessionTicketData{
+		Raw:    rawData,
+		Valid:  true,
+		Ticket: rawData,
+	}
+}
+
+// pre_shared_key - RFC 8446, Section 4.2.11
+type PreSharedKeyData struct {
+	Raw        []byte        `json:"raw"`
+	Valid      bool          `json:"valid"`
+	Identities []PSKIdentity `json:"identities"`
+	Binders    [][]byte      `json:"binders"`
+}
+
+type PSKIdentity struct {
+	Identity            []byte `json:"identity"`
+	ObfuscatedTicketAge uint32 `json:"obfuscated_ticket_age"`
+}
+
+func ParsePreSharedKeyData(rawData []byte) ExtensionData {
+	parsedData := &PreSharedKeyData{Raw: rawData, Identities: []PSKIdentity{}, Binders: [][]byte{}}
+	data := cryptobyte.String(rawData)
+	var identities, binders cryptobyte.String
+	if !data.ReadUint16LengthPrefixed(&identities) || identities.Empty() {
+		return parsedData
+	}
+	for !identities.Empty() {
+		var identity PSKIdentity
+		if !identities.ReadUint16LengthPrefixed((*cryptobyte.String)(&identity.Identity)) || len(identity.Identity) == 0 || !identities.ReadUint32(&identity.ObfuscatedTicketAge) {
+			return parsedData
+		}
+		parsedData.Identities = append(parsedData.Identities, identity)
+	}
+	if !data.ReadUint16LengthPrefixed(&binders) || binders.Empty() {
+		return parsedData
+	}
+	for !binders.Empty() {
+		var binder cryptobyte.String
+		if !binders.ReadUint8LengthPrefixed(&binder) || len(binder) < 32 {
+			return parsedData
+		}
+		parsedData.Binders = append(parsedData.Binders, binder)
+	}
+	if !data.Empty() || len(parsedData.Binders) != len(parsedData.Identities) {
+		return parsedData
+	}
+	parsedData.Valid = true
+	return parsedData
+}
+
+// psk_key_exchange_modes - RFC 8446, Section 4.2.9
+type PSKKeyExchangeModesData struct {
+	Raw   []byte   `json:"raw"`
+	Valid bool     `json:"valid"`
+	Modes []uint16 `json:"modes"` // 0 = psk_ke, 1 = psk_dhe_ke
+}
+
+func ParsePSKKeyExchangeModesData(rawData []byte) ExtensionData {
+	parsedData := &PSKKeyExchangeModesData{Raw: rawData, Modes: []uint16{}}
+	data := cryptobyte.String(rawData)
+	var list cryptobyte.String
+	if !data.ReadUint8LengthPrefixed(&list) || list.Empty() {
+		return parsedData
+	}
+	for _, mode := range list {
+		parsedData.Modes = append(parsedData.Modes, uint16(mode))
+	}
+	if !data.Empty() {
+		return parsedData
+	}
+	parsedData.Valid = true
+	return parsedData
+}
+
+// Certificate compression algorithms from https://www.iana.org/assignments/tls-parameters/tls-parameters.xhtml#tls-parameters-97
+var CertificateCompressionAlgorithms = map[uint16]string{
+	1: "zlib",
+	2: "brotli",
+	3: "zstd",
+}
+
+type CertificateCompressionAlgorithm struct {
+	Code uint16 `json:"code"`
+	Name string `json:"name,omitempty"`
+}
+
+// compress_certificate - RFC 8879, Section 3
+type CompressCertificateData struct {
+	Raw        []byte                            `json:"raw"`
+	Valid      bool                              `json:"valid"`
+	Algorithms []CertificateCompressionAlgorithm `json:"algorithms"`
+}
+
+func ParseCompressCertificateData(rawData []byte) ExtensionData {
+	parsedData := &CompressCertificateData{Raw: rawData, Algorithms: []CertificateCompressionAlgorithm{}}
+	data := cryptobyte.String(rawData)
+	var list cryptobyte.String
+	if !data.ReadUint8LengthPrefixed(&list) || list.Empty() {
+		return parsedData
+	}
+	for !list.Empty() {
+		var code uint16
+		if !list.ReadUint16(&code) {
+			return parsedData
+		}
+		parsedData.Algorithms = append(parsedData.Algorithms, CertificateCompressionAlgorithm{Code: code, Name: CertificateCompressionAlgorithms[code]})
+	}
+	if !data.Empty() {
+		return parsedData
+	}
+	parsedData.Valid = true
+	return parsedData
+}
+
+// record_size_limit - RFC 8449, Section 4
+type RecordSizeLimitData struct {
+	Raw   []byte `json:"raw"`
+	Valid bool   `json:"valid"`
+	Limit uint16 `json:"limit"`
+}
+
+func ParseRecordSizeLimitData(rawData []byte) ExtensionData {
+	parsedData := &RecordSizeLimitData{Raw: rawData}
+	data := cryptobyte.String(rawData)
+	if !data.ReadUint16(&parsedData.Limit) || !data.Empty() || parsedData.Limit < 64 {
+		return parsedData
+	}
+	parsedData.Valid = true
+	return parsedData
+}
+
+// application_settings - draft-vvv-tls-alps, Section 3
+type ApplicationSettingsData struct {
+	Raw       []byte   `json:"raw"`
+	Valid     bool     `json:"valid"`
+	Protocols []string `json:"protocols"` // ALPN protocols for which the client supports ALPS
+}
+
+func ParseApplicationSettingsData(rawData []byte) ExtensionData {
+	alpnData := ParseALPNData(rawData).(*ALPNData)
+	return &ApplicationSettingsData{
+		Raw:       alpnData.Raw,
+		Valid:     alpnData.Valid,
+		Protocols: alpnData.Protocols,
+	}
+}
+
+// max_fragment_length - RFC 6066, Section 4
+type MaxFragmentLengthData struct {
+	Raw    []byte `json:"raw"`
+	Valid  bool   `json:"valid"`
+	Code   uint8  `json:"code"`
+	Length int    `json:"length"` // 0 if Code is unknown
+}
+
+func ParseMaxFragmentLengthData(rawData []byte) ExtensionData {
+	parsedData := &MaxFragmentLengthData{Raw: rawData}
+	data := cryptobyte.String(rawData)
+	if !data.ReadUint8(&parsedData.Code) || !data.Empty() {
+		return parsedData
+	}
+	if parsedData.Code < 1 || parsedData.Code > 4 {
+		return parsedData
+	}
+	parsedData.Length = 1 << (8 + parsedData.Code)
+	parsedData.Valid = true
+	return parsedData
+}
+
+// SRTP protection profiles from https://www.iana.org/assignments/srtp-protection/srtp-protection.xhtml
+var SRTPProtectionProfiles = map[uint16]string{
+	0x0001: "SRTP_AES128_CM_HMAC_SHA1_80",
+	0x0002: "SRTP_AES128_CM_HMAC_SHA1_32",
+	0x0005: "SRTP_NULL_HMAC_SHA1_80",
+	0x0006: "SRTP_NULL_HMAC_SHA1_32",
+	0x0007: "SRTP_AEAD_AES_128_GCM",
+	0x0008: "SRTP_AEAD_AES_256_GCM",
+	0x0009: "DOUBLE_AEAD_AES_128_GCM_AEAD_AES_128_GCM",
+	0x000a: "DOUBLE_AEAD_AES_256_GCM_AEAD_AES_256_GCM",
+	0x000b: "SRTP_ARIA_128_CTR_HMAC_SHA1_80",
+	0x000c: "SRTP_ARIA_128_CTR_HMAC_SHA1_32",
+	0x000d: "SRTP_ARIA_256_CTR_HMAC_SHA1_80",
+	0x000e: "SRTP_ARIA_256_CTR_HMAC_SHA1_32",
+	0x000f: "SRTP_AEAD_ARIA_128_GCM",
+	0x0010: "SRTP_AEAD_ARIA_256_GCM",
+}
+
+type SRTPProtectionProfile struct {
+	Code uint16 `json:"code"`
+	Name string `json:"name,omitempty"`
+}
+
+// use_srtp - RFC 5764, Section 4.1.1
+type UseSRTPData struct {
+	Raw      []byte                  `json:"raw"`
+	Valid    bool                    `json:"valid"`
+	Profiles []SRTPProtectionProfile `json:"profiles"`
+	MKI      []byte                  `json:"mki"`
+}
+
+func ParseUseSRTPData(rawData []byte) ExtensionData {
+	parsedData := &UseSRTPData{Raw: rawData, Profiles: []SRTPProtectionProfile{}}
+	data := cryptobyte.String(rawData)
+	var list cryptobyte.String
+	if !data.ReadUint16LengthPrefixed(&list) || list.Empty() {
+		return parsedData
+	}
+	for !list.Empty() {
+		var code uint16
+		if !list.ReadUint16(&code) {
+			return parsedData
+		}
+		parsedData.Profiles = append(parsedData.Profiles, SRTPProtectionProfile{Code: code, Name: SRTPProtectionProfiles[code]})
+	}
+	if !data.ReadUint8LengthPrefixed((*cryptobyte.String)(&parsedData.MKI)) || !data.Empty() {
+		return parsedData
+	}
+	parsedData.Valid = true
+	return parsedData
+}
+
+// heartbeat - RFC 6520, Section 2
+type HeartbeatData struct {
+	Raw      []byte `json:"raw"`
+	Valid    bool   `json:"valid"`
+	Mode     uint8  `json:"mode"`
+	ModeName string `json:"mode_name,omitempty"`
+}
+
+func ParseHeartbeatData(rawData []byte) ExtensionData {
+	parsedData := &HeartbeatData{Raw: rawData}
+	data := cryptobyte.String(rawData)
+	if !data.ReadUint8(&parsedData.Mode) || !data.Empty() {
+		return parsedData
+	}
+	switch parsedData.Mode {
+	case 1:
+		parsedData.ModeName = "peer_allowed_to_send"
+	case 2:
+		parsedData.ModeName = "peer_not_allowed_to_send"
+	default:
+		return parsedData
+	}
+	parsedData.Valid = true
+	return parsedData
+}
+
+// padding - RFC 7685, Section 3
+type PaddingData struct {
+	Raw     []byte `json:"raw"`
+	Valid   bool   `json:"valid"` // true if the padding is all zero
+	Length  int    `json:"length"`
+	AllZero bool   `json:"all_zero"`
+}
+
+func ParsePaddingData(rawData []byte) ExtensionData {
+	parsedData := &PaddingData{Raw: rawData, Length: len(rawData), AllZero: true}
+	for _, b := range rawData {
+		if b != 0 {
+			parsedData.AllZero = false
+		}
+	}
+	parsedData.Valid = parsedData.AllZero
+	return parsedData
+}
+
+// token_binding - RFC 8472, Section 2
+type TokenBindingData struct {
+	Raw           []byte   `json:"raw"`
+	Valid         bool     `json:"valid"`
+	MajorVersion  uint8    `json:"major_version"`
+	MinorVersion  uint8    `json:"minor_version"`
+	KeyParameters []uint16 `json:"key_parameters"` // 0 = rsa2048_pkcs1.5, 1 = rsa2048_pss, 2 = ecdsap256
+}
+
+func ParseTokenBindingData(rawData []byte) ExtensionData {
+	parsedData := &TokenBindingData{Raw: rawData, KeyParameters: []uint16{}}
+	data := cryptobyte.String(rawData)
+	var list cryptobyte.String
+	if !data.ReadUint8(&parsedData.MajorVersion) || !data.ReadUint8(&parsedData.MinorVersion) {
+		return parsedData
+	}
+	if !data.ReadUint8LengthPrefixed(&list) || list.Empty() || !data.Empty() {
+		return parsedData
+	}
+	for _, parameter := range list {
+		parsedData.KeyParameters = append(parsedData.KeyParameters, uint16(parameter))
+	}
+	parsedData.Valid = true
+	return parsedData
+}
+
+// renegotiation_info - RFC 5746, Section 3.2
+type RenegotiationInfoData struct {
+	Raw                    []byte `json:"raw"`
+	Valid                  bool   `json:"valid"`
+	RenegotiatedConnection []byte `json:"renegotiated_connection"` // the client's verify_data, empty in an initial handshake
+	VerifyDataLength       int    `json:"verify_data_length"`
+}
+
+func ParseRenegotiationInfoData(rawData []byte) ExtensionData {
+	parsedData := &RenegotiationInfoData{Raw: rawData}
+	data := cryptobyte.String(rawData)
+	if !data.ReadUint8LengthPrefixed((*cryptobyte.String)(&parsedData.RenegotiatedConnection)) || !data.Empty() {
+		return parsedData
+	}
+	parsedData.VerifyDataLength = len(parsedData.RenegotiatedConnection)
+	parsedData.Valid = true
+	return parsedData
+}
+
+var extensionParsers = map[uint16]ExtensionParser{
 	0:  ParseServerNameData,
+	1:  ParseMaxFragmentLengthData,
+	3:  ParseTrustedCAKeysData,
+	5:  ParseStatusRequestData,
 	10: ParseSupportedGroupsData,
 	11: ParseECPointFormatsData,
+	13: ParseSignatureAlgorithmsData,
+	14: ParseUseSRTPData,
+	15: ParseHeartbeatData,
 	16: ParseALPNData,
+	17: ParseStatusRequestV2Data,
 	18: ParseEmptyExtensionData,
-	22: ParseEmptyExtensionData,
-	23: ParseEmptyExtensionData,
+	19: ParseCertificateTypeData,
+	20: ParseCertificateTypeData,
+	21: ParsePaddingData,
+	22: ParseEmptyExtensionData, // encrypt_then_mac
+	23: ParseEmptyExtensionData, // extended_master_secret
+	24: ParseTokenBindingData,
+	27: ParseCompressCertificateData,
+	28: ParseRecordSizeLimitData,
+	34: ParseSignatureAlgorithmsData, // delegated_credentials (RFC 9345, Section 4.1.1)
+	35: ParseSessionTicketData,
+	41: ParsePreSharedKeyData,
+	42: ParseEmptyExtensionData,
+	43: ParseSupportedVersionsData,
+	45: ParsePSKKeyExchangeModesData,
+	47: ParseCertificateAuthoritiesData,
 	49: ParseEmptyExtensionData,
+	50: ParseSignatureAlgorithmsData,
+	51: ParseKeyShareData,
+
+	17513:  ParseApplicationSettingsData,
+	17613:  ParseApplicationSettingsData,
+	0xff01: ParseRenegotiationInfoData,
 }
@@ -0,0 +1,176 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package tlshacks
+
+import (
+	"errors"
+	"sync"
+
+	"golang.org/x/crypto/cryptobyte"
+)
+
+// maxCapturedHandshake limits how many bytes of handshake messages are captured after the ClientHello
+const maxCapturedHandshake = 1 << 20
+
+// ClientFlight describes what the client sent after its ClientHello, up to the point where
+// encryption began.  In TLS 1.2 and below, this includes the client's Certificate,
+// ClientKeyExchange, and CertificateVerify messages.  In TLS 1.3, everything after
+// the ClientHello is encrypted, so at most a ChangeCipherSpec is visible.
+type ClientFlight struct {
+	Messages []HandshakeMessage `json:"messages"` // plaintext handshake messages, in order
+	Sequence []string           `json:"sequence"` // names of the messages and records, in the order they were sent
+	Complete bool               `json:"complete"` // whether encryption began, so there is nothing more to capture
+}
+
+// Message returns the first message with the given type, or nil if there isn't one
+func (flight *ClientFlight) Message(messageType uint8) *HandshakeMessage {
+	for i := range flight.Messages {
+		if flight.Messages[i].Type == messageType {
+			return &flight.Messages[i]
+		}
+	}
+	return nil
+}
+
+// handshakeCapture is an io.Writer which parses the records sent by the client after the
+// ClientHello, stopping once encryption begins
+type handshakeCapture struct {
+	mu                sync.Mutex
+	records           []byte // incomplete record
+	handshake         []byte // incomplete handshake message
+	changedCipherSpec bool
+	flight            ClientFlight
+	done              bool
+}
+
+func (c *handshakeCapture) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.done {
+		return len(p), nil
+	}
+	c.records = append(c.records, p...)
+	for !c.done && len(c.records) >= 5 {
+		length := int(c.records[3])<<8 | int(c.records[4])
+		if len(c.records) < 5+length {
+			break
+		}
+		c.record(c.records[0], c.records[5:5+length])
+		c.records = c.records[5+length:]
+	}
+	if c.done {
+		c.records, c.handshake = nil, nil
+	}
+	return len(p), nil
+}
+
+func (c *handshakeCapture) record(contentType uint8, fragment []byte) {
+	switch {
+	case contentType == 20:
+		c.changedCipherSpec = true
+		c.flight.Sequence = append(c.flight.Sequence, "change_cipher_spec")
+	case contentType == 22 && !c.changedCipherSpec:
+		c.handshake = append(c.handshake, fragment...)
+		for len(c.handshake) >= 4 {
+			length := int(c.handshake[1])<<16 | int(c.handshake[2])<<8 | int(c.handshake[3])
+			if len(c.handshake) < 4+length {
+				break
+			}
+			message := MakeHandshakeMessage(append([]byte(nil), c.handshake[:4+length]...))
+			c.flight.Messages = append(c.flight.Messages, message)
+			c.flight.Sequence = append(c.flight.Sequence, message.Name)
+			c.handshake = c.handshake[4+length:]
+		}
+		if len(c.handshake) > maxCapturedHandshake {
+			c.done = true
+		}
+	case contentType == 22:
+		// In TLS 1.2 and below, the first handshake message after ChangeCipherSpec is the encrypted Finished
+		c.flight.Sequence = append(c.flight.Sequence, "finished (encrypted)")
+		c.flight.Complete = true
+		c.done = true
+	case contentType == 21 && !c.changedCipherSpec && len(fragment) == 2:
+		c.flight.Sequence = append(c.flight.Sequence, MakeAlert(fragment[0], fragment[1]).String())
+		c.done = true
+	default:
+		c.flight.Sequence = append(c.flight.Sequence, "encrypted record")
+		c.flight.Complete = true
+		c.done = true
+	}
+}
+
+func (c *handshakeCapture) clientFlight() *ClientFlight {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return &ClientFlight{
+		Messages: append([]HandshakeMessage{}, c.flight.Messages...),
+		Sequence: append([]string{}, c.flight.Sequence...),
+		Complete: c.flight.Complete,
+	}
+}
+
+// ParseCertificateMessage returns the certificates in a TLS 1.2 (or earlier) Certificate message
+func ParseCertificateMessage(message *HandshakeMessage) ([][]byte, error) {
+	body := cryptobyte.String(message.Body())
+	var list cryptobyte.String
+	if !body.ReadUint24LengthPrefixed(&list) || !body.Empty() {
+		return nil, errors.New("malformed Certificate message")
+	}
+	certs := [][]byte{}
+	for !list.Empty() {
+		var cert []byte
+		if !list.ReadUint24LengthPrefixed((*cryptobyte.String)(&cert)) {
+			return nil, errors.New("malformed certificate in Certificate message")
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+// CertificateVerify is a parsed CertificateVerify message (RFC 5246, Section 7.4.8)
+type CertificateVerify struct {
+	Algorithm     *uint16 `json:"algorithm"` // SignatureScheme; nil in TLS 1.1 and below, which don't have this field
+	AlgorithmName string  `json:"algorithm_name,omitempty"`
+	Signature     []byte  `json:"signature"`
+}
+
+// ParseCertificateVerify parses a CertificateVerify message sent using the given TLS version
+func ParseCertificateVerify(message *HandshakeMessage, version uint16) (*CertificateVerify, error) {
+	body := cryptobyte.String(message.Body())
+	verify := new(CertificateVerify)
+	if version >= 0x0303 {
+		var algorithm uint16
+		if !body.ReadUint16(&algorithm) {
+			return nil, errors.New("malformed CertificateVerify message")
+		}
+		verify.Algorithm = &algorithm
+		verify.AlgorithmName = SignatureAlgorithmNames[algorithm]
+	}
+	if !body.ReadUint16LengthPrefixed((*cryptobyte.String)(&verify.Signature)) || !body.Empty() {
+		return nil, errors.New("malformed CertificateVerify message")
+	}
+	return verify, nil
+}
@@ -0,0 +1,252 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package tlshacks
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+func testHandshakeMessage(messageType uint8, body []byte) []byte {
+	return concat([]byte{messageType}, lengthPrefixed(3, body))
+}
+
+func testRecord(contentType uint8, fragment []byte) []byte {
+	return concat([]byte{contentType, 3, 3}, lengthPrefixed(2, fragment))
+}
+
+// splitEvery splits data into chunks of n bytes, simulating a stream written in pieces
+func splitEvery(data []byte, n int) [][]byte {
+	var chunks [][]byte
+	for len(data) > n {
+		chunks = append(chunks, data[:n])
+		data = data[n:]
+	}
+	return append(chunks, data)
+}
+
+func TestHandshakeCapture(t *testing.T) {
+	certificate := testHandshakeMessage(11, lengthPrefixed(3, lengthPrefixed(3, []byte("certificate"))))
+	clientKeyExchange := testHandshakeMessage(16, lengthPrefixed(1, bytes.Repeat([]byte{0x42}, 32)))
+	certificateVerify := testHandshakeMessage(15, concat([]byte{0x04, 0x01}, lengthPrefixed(2, []byte("signature"))))
+	flight := concat(certificate, clientKeyExchange, certificateVerify)
+	changeCipherSpec := testRecord(20, []byte{1})
+	encryptedFinished := testRecord(22, bytes.Repeat([]byte{0x99}, 40))
+	applicationData := testRecord(23, bytes.Repeat([]byte{0x99}, 40))
+	fullFlightNames := []string{"certificate", "client_key_exchange", "certificate_verify"}
+	fullFlightSequence := []string{"certificate", "client_key_exchange", "certificate_verify", "change_cipher_spec", "finished (encrypted)"}
+
+	// A handshake message which can't be buffered without exceeding maxCapturedHandshake
+	oversized := testHandshakeMessage(11, make([]byte, 2*maxCapturedHandshake))
+	var oversizedRecords []byte
+	for _, fragment := range splitEvery(oversized, 16384) {
+		oversizedRecords = append(oversizedRecords, testRecord(22, fragment)...)
+	}
+
+	tests := []struct {
+		name     string
+		writes   [][]byte
+		messages []string
+		sequence []string
+		complete bool
+	}{
+		{
+			name:     "one record",
+			writes:   [][]byte{concat(testRecord(22, flight), changeCipherSpec, encryptedFinished)},
+			messages: fullFlightNames,
+			sequence: fullFlightSequence,
+			complete: true,
+		},
+		{
+			name:     "record per message",
+			writes:   [][]byte{testRecord(22, certificate), testRecord(22, clientKeyExchange), testRecord(22, certificateVerify), changeCipherSpec, encryptedFinished},
+			messages: fullFlightNames,
+			sequence: fullFlightSequence,
+			complete: true,
+		},
+		{
+			name:     "message split across records",
+			writes:   [][]byte{concat(testRecord(22, flight[:2]), testRecord(22, flight[2:7]), testRecord(22, flight[7:]), changeCipherSpec, encryptedFinished)},
+			messages: fullFlightNames,
+			sequence: fullFlightSequence,
+			complete: true,
+		},
+		{
+			name:     "record split across writes",
+			writes:   splitEvery(concat(testRecord(22, flight), changeCipherSpec, encryptedFinished), 1),
+			messages: fullFlightNames,
+			sequence: fullFlightSequence,
+			complete: true,
+		},
+		{
+			name:     "message and record split across writes",
+			writes:   splitEvery(concat(testRecord(22, flight[:10]), testRecord(22, flight[10:]), changeCipherSpec, encryptedFinished), 3),
+			messages: fullFlightNames,
+			sequence: fullFlightSequence,
+			complete: true,
+		},
+		{
+			name:     "records after encrypted finished",
+			writes:   [][]byte{concat(testRecord(22, flight), changeCipherSpec, encryptedFinished), applicationData, testRecord(22, certificate)},
+			messages: fullFlightNames,
+			sequence: fullFlightSequence,
+			complete: true,
+		},
+		{
+			name:     "incomplete flight",
+			writes:   [][]byte{concat(testRecord(22, certificate), testRecord(22, clientKeyExchange[:10]))},
+			messages: []string{"certificate"},
+			sequence: []string{"certificate"},
+			complete: false,
+		},
+		{
+			name:     "alert",
+			writes:   [][]byte{concat(testRecord(22, certificate), testRecord(21, []byte{2, 40}), changeCipherSpec)},
+			messages: []string{"certificate"},
+			sequence: []string{"certificate", "fatal alert handshake_failure (40)"},
+			complete: false,
+		},
+		{
+			name:     "TLS 1.3",
+			writes:   [][]byte{changeCipherSpec, applicationData},
+			messages: []string{},
+			sequence: []string{"change_cipher_spec", "encrypted record"},
+			complete: true,
+		},
+		{
+			name:     "TLS 1.3 without change_cipher_spec",
+			writes:   [][]byte{applicationData, testRecord(22, certificate)},
+			messages: []string{},
+			sequence: []string{"encrypted record"},
+			complete: true,
+		},
+		{
+			name:     "maxCapturedHandshake exceeded",
+			writes:   [][]byte{oversizedRecords, changeCipherSpec, encryptedFinished},
+			messages: []string{},
+			sequence: []string{},
+			complete: false,
+		},
+	}
+	for _, test := range tests {
+		capture := new(handshakeCapture)
+		for _, p := range test.writes {
+			if n, err := capture.Write(p); n != len(p) || err != nil {
+				t.Fatalf("%s: Write returned %d, %v", test.name, n, err)
+			}
+		}
+		flight := capture.clientFlight()
+		names := []string{}
+		for _, message := range flight.Messages {
+			names = append(names, message.Name)
+		}
+		if !slices.Equal(names, test.messages) {
+			t.Errorf("%s: messages are %q, expected %q", test.name, names, test.messages)
+		}
+		if !slices.Equal(flight.Sequence, test.sequence) {
+			t.Errorf("%s: sequence is %q, expected %q", test.name, flight.Sequence, test.sequence)
+		}
+		if flight.Complete != test.complete {
+			t.Errorf("%s: complete is %t, expected %t", test.name, flight.Complete, test.complete)
+		}
+		if message := flight.Message(HandshakeTypeCertificateVerify); message != nil && !bytes.Equal(message.Raw, certificateVerify) {
+			t.Errorf("%s: certificate_verify is %x, expected %x", test.name, message.Raw, certificateVerify)
+		}
+	}
+}
+
+func TestParseCertificateMessage(t *testing.T) {
+	tests := []struct {
+		body  []byte
+		certs [][]byte // nil if malformed
+	}{
+		{lengthPrefixed(3, nil), [][]byte{}},
+		{lengthPrefixed(3, lengthPrefixed(3, []byte("leaf"))), [][]byte{[]byte("leaf")}},
+		{lengthPrefixed(3, concat(lengthPrefixed(3, []byte("leaf")), lengthPrefixed(3, []byte("intermediate")))), [][]byte{[]byte("leaf"), []byte("intermediate")}},
+		{nil, nil},
+		{concat(lengthPrefixed(3, nil), []byte{0}), nil},
+		{lengthPrefixed(3, []byte{0, 0, 5, 'l', 'e'}), nil},
+	}
+	for _, test := range tests {
+		message := MakeHandshakeMessage(testHandshakeMessage(11, test.body))
+		certs, err := ParseCertificateMessage(&message)
+		if test.certs == nil {
+			if err == nil {
+				t.Errorf("%x: malformed message parsed as %q", test.body, certs)
+			}
+		} else if err != nil {
+			t.Errorf("%x: %s", test.body, err)
+		} else if !slices.EqualFunc(certs, test.certs, bytes.Equal) {
+			t.Errorf("%x: certificates are %q, expected %q", test.body, certs, test.certs)
+		}
+	}
+}
+
+func TestParseCertificateVerify(t *testing.T) {
+	signature := []byte("signature")
+	tests := []struct {
+		body          []byte
+		version       uint16
+		malformed     bool
+		algorithm     *uint16
+		algorithmName string
+	}{
+		{concat([]byte{0x04, 0x01}, lengthPrefixed(2, signature)), 0x0303, false, ptrTo(uint16(0x0401)), "rsa_pkcs1_sha256"},
+		{concat([]byte{0xfe, 0xfe}, lengthPrefixed(2, signature)), 0x0303, false, ptrTo(uint16(0xfefe)), ""},
+		{lengthPrefixed(2, signature), 0x0302, false, nil, ""},
+		{lengthPrefixed(2, signature), 0x0301, false, nil, ""},
+		{concat([]byte{0x04, 0x01}, lengthPrefixed(2, signature)), 0x0302, true, nil, ""}, // TLS 1.2 message sent with TLS 1.1
+		{lengthPrefixed(2, signature), 0x0303, true, nil, ""},                             // TLS 1.1 message sent with TLS 1.2
+		{[]byte{0x04}, 0x0303, true, nil, ""},
+		{concat([]byte{0x04, 0x01}, lengthPrefixed(2, signature), []byte{0}), 0x0303, true, nil, ""},
+	}
+	for _, test := range tests {
+		message := MakeHandshakeMessage(testHandshakeMessage(15, test.body))
+		verify, err := ParseCertificateVerify(&message, test.version)
+		if test.malformed {
+			if err == nil {
+				t.Errorf("%x (version %04x): malformed message parsed as %+v", test.body, test.version, verify)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%x (version %04x): %s", test.body, test.version, err)
+			continue
+		}
+		if (verify.Algorithm == nil) != (test.algorithm == nil) || (verify.Algorithm != nil && *verify.Algorithm != *test.algorithm) {
+			t.Errorf("%x (version %04x): algorithm is %v, expected %v", test.body, test.version, verify.Algorithm, test.algorithm)
+		}
+		if verify.AlgorithmName != test.algorithmName {
+			t.Errorf("%x (version %04x): algorithm name is %q, expected %q", test.body, test.version, verify.AlgorithmName, test.algorithmName)
+		}
+		if !bytes.Equal(verify.Signature, signature) {
+			t.Errorf("%x (version %04x): signature is %x, expected %x", test.body, test.version, verify.Signature, signature)
+		}
+	}
+}
+
+func ptrTo[T any](v T) *T { return &v }
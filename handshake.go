@@ -0,0 +1,80 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package tlshacks
+
+// Handshake message types from https://www.iana.org/assignments/tls-parameters/tls-parameters.xhtml#tls-parameters-7
+var HandshakeTypes = map[uint8]string{
+	0:   "hello_request",
+	1:   "client_hello",
+	2:   "server_hello",
+	3:   "hello_verify_request",
+	4:   "new_session_ticket",
+	5:   "end_of_early_data",
+	6:   "hello_retry_request",
+	8:   "encrypted_extensions",
+	9:   "request_connection_id",
+	10:  "new_connection_id",
+	11:  "certificate",
+	12:  "server_key_exchange",
+	13:  "certificate_request",
+	14:  "server_hello_done",
+	15:  "certificate_verify",
+	16:  "client_key_exchange",
+	17:  "client_certificate_request",
+	20:  "finished",
+	21:  "certificate_url",
+	22:  "certificate_status",
+	23:  "supplemental_data",
+	24:  "key_update",
+	25:  "compressed_certificate",
+	26:  "ekt_key",
+	254: "message_hash",
+}
+
+const (
+	HandshakeTypeCertificate       = 11
+	HandshakeTypeCertificateVerify = 15
+)
+
+// HandshakeMessage is a complete handshake message, including its 4 byte header
+type HandshakeMessage struct {
+	Type uint8  `json:"type"`
+	Name string `json:"name,omitempty"`
+	Raw  []byte `json:"raw"`
+}
+
+func MakeHandshakeMessage(raw []byte) HandshakeMessage {
+	return HandshakeMessage{
+		Type: raw[0],
+		Name: HandshakeTypes[raw[0]],
+		Raw:  raw,
+	}
+}
+
+// Body returns the message without its header
+func (message *HandshakeMessage) Body() []byte {
+	return message.Raw[4:]
+}
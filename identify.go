@@ -0,0 +1,327 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package tlshacks
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+)
+
+// ClientSignature describes the ClientHellos sent by a particular client.  All of the
+// fields other than Product and Versions are optional; fields which are omitted are
+// not considered when matching.  GREASE values must not be included in any of the lists.
+type ClientSignature struct {
+	Product  string `json:"product"`  // e.g. "Firefox"
+	Versions string `json:"versions"` // e.g. "128+" or "1.21-1.23"
+
+	JA3 []string `json:"ja3,omitempty"` // JA3 fingerprints (hashed)
+	JA4 []string `json:"ja4,omitempty"` // JA4 fingerprints (hashed)
+
+	CipherSuites        []uint16 `json:"cipher_suites,omitempty"`        // compared in order
+	Extensions          []uint16 `json:"extensions,omitempty"`           // compared without regard to order, since some clients permute extensions
+	Groups              []uint16 `json:"groups,omitempty"`               // compared in order
+	SignatureAlgorithms []uint16 `json:"signature_algorithms,omitempty"` // compared in order
+	Grease              *bool    `json:"grease,omitempty"`               // whether the client sends GREASE values
+
+	CertificateCompression []uint16 `json:"certificate_compression,omitempty"` // compress_certificate algorithms, compared in order
+	DelegatedCredentials   []uint16 `json:"delegated_credentials,omitempty"`   // delegated_credentials signature algorithms, compared in order
+	ApplicationSettings    []string `json:"application_settings,omitempty"`    // ALPS protocols, compared in order
+	RecordSizeLimit        uint16   `json:"record_size_limit,omitempty"`
+}
+
+func (sig *ClientSignature) String() string {
+	if sig.Versions == "" {
+		return sig.Product
+	}
+	return sig.Product + " " + sig.Versions
+}
+
+type Match struct {
+	Product    string  `json:"product"`
+	Versions   string  `json:"versions"`
+	Confidence float64 `json:"confidence"` // between 0 and 1
+	Reason     string  `json:"reason"`     // "ja4", "ja3", or "structure"
+}
+
+func (m Match) String() string {
+	if m.Versions == "" {
+		return m.Product
+	}
+	return m.Product + " " + m.Versions
+}
+
+type Database struct {
+	Clients []ClientSignature `json:"clients"`
+}
+
+//go:embed clients.json
+var defaultDatabaseJSON []byte
+
+// DefaultDatabase contains signatures for common clients.  It is used to populate
+// the client info in ClientHelloInfo.
+var DefaultDatabase = mustLoadDatabase(defaultDatabaseJSON)
+
+func mustLoadDatabase(data []byte) *Database {
+	db, err := LoadDatabase(bytes.NewReader(data))
+	if err != nil {
+		panic(err)
+	}
+	return db
+}
+
+// LoadDatabase reads a database in JSON format, which contains a "clients" array of ClientSignature objects
+func LoadDatabase(r io.Reader) (*Database, error) {
+	db := new(Database)
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(db); err != nil {
+		return nil, fmt.Errorf("error parsing client database: %w", err)
+	}
+	for i := range db.Clients {
+		if db.Clients[i].Product == "" {
+			return nil, fmt.Errorf("client database entry %d lacks a product name", i)
+		}
+	}
+	return db, nil
+}
+
+func LoadDatabaseFile(filename string) (*Database, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return LoadDatabase(file)
+}
+
+func sendsGrease(hello *ClientHelloInfo) bool {
+	return slices.ContainsFunc(hello.CipherSuites, func(suite CipherSuite) bool { return suite.Grease }) ||
+		slices.ContainsFunc(hello.Extensions, func(ext Extension) bool { return ext.Grease })
+}
+
+func certificateCompressionOf(hello *ClientHelloInfo) []uint16 {
+	return extensionList(hello, 27, func(data ExtensionData) ([]uint16, bool) {
+		d, ok := data.(*CompressCertificateData)
+		if !ok {
+			return nil, false
+		}
+		var algorithms []uint16
+		for _, algorithm := range d.Algorithms {
+			algorithms = append(algorithms, algorithm.Code)
+		}
+		return algorithms, true
+	})
+}
+
+func delegatedCredentialsOf(hello *ClientHelloInfo) []uint16 {
+	return extensionList(hello, 34, func(data ExtensionData) ([]uint16, bool) {
+		d, ok := data.(*SignatureAlgorithmsData)
+		if !ok {
+			return nil, false
+		}
+		return nonGrease(d.Algorithms, codeOf), true
+	})
+}
+
+// applicationSettingsOf returns the ALPS protocols from either application_settings codepoint
+func applicationSettingsOf(hello *ClientHelloInfo) []string {
+	list := func(data ExtensionData) ([]string, bool) {
+		d, ok := data.(*ApplicationSettingsData)
+		if !ok {
+			return nil, false
+		}
+		return d.Protocols, true
+	}
+	if protocols := extensionList(hello, 17613, list); protocols != nil {
+		return protocols
+	}
+	return extensionList(hello, 17513, list)
+}
+
+func recordSizeLimitOf(hello *ClientHelloInfo) uint16 {
+	if ext := hello.Extension(28); ext != nil {
+		if d, ok := ext.Data.(*RecordSizeLimitData); ok {
+			return d.Limit
+		}
+	}
+	return 0
+}
+
+// SignatureOf returns a ClientSignature that matches hello, suitable for adding to a database
+func SignatureOf(hello *ClientHelloInfo, product string, versions string) ClientSignature {
+	grease := sendsGrease(hello)
+	extensions := extensionTypesOf(hello)
+	slices.Sort(extensions)
+	return ClientSignature{
+		Product:                product,
+		Versions:               versions,
+		JA3:                    []string{hello.Info.JA3Fingerprint},
+		JA4:                    []string{hello.Info.JA4Fingerprint},
+		CipherSuites:           cipherSuitesOf(hello),
+		Extensions:             extensions,
+		Groups:                 groupsOf(hello),
+		SignatureAlgorithms:    signatureAlgorithmsOf(hello),
+		Grease:                 &grease,
+		CertificateCompression: certificateCompressionOf(hello),
+		DelegatedCredentials:   delegatedCredentialsOf(hello),
+		ApplicationSettings:    applicationSettingsOf(hello),
+		RecordSizeLimit:        recordSizeLimitOf(hello),
+	}
+}
+
+// Confidence levels assigned to each type of match.  Structural matches are scaled by
+// how similar the ClientHello is to the signature, and are never as confident as a JA4
+// match because the signature may be incomplete.
+const (
+	ja4Confidence       = 1.0
+	ja3Confidence       = 0.95
+	structureConfidence = 0.9
+	minimumConfidence   = 0.75
+)
+
+// orderedSimilarity returns the length of the longest common subsequence of a and b,
+// relative to the length of the longer list
+func orderedSimilarity[T comparable](a, b []T) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+	return float64(lengths[0][0]) / float64(max(len(a), len(b)))
+}
+
+// unorderedSimilarity returns the Jaccard index of a and b
+func unorderedSimilarity[T comparable](a, b []T) float64 {
+	setA := make(map[T]bool)
+	setB := make(map[T]bool)
+	union := make(map[T]bool)
+	for _, elem := range a {
+		setA[elem] = true
+		union[elem] = true
+	}
+	for _, elem := range b {
+		setB[elem] = true
+		union[elem] = true
+	}
+	if len(union) == 0 {
+		return 1
+	}
+	intersection := 0
+	for elem := range setA {
+		if setB[elem] {
+			intersection++
+		}
+	}
+	return float64(intersection) / float64(len(union))
+}
+
+func (sig *ClientSignature) structuralSimilarity(hello *ClientHelloInfo) (float64, bool) {
+	var total float64
+	var count int
+	if sig.CipherSuites != nil {
+		total += orderedSimilarity(sig.CipherSuites, cipherSuitesOf(hello))
+		count++
+	}
+	if sig.Extensions != nil {
+		total += unorderedSimilarity(sig.Extensions, extensionTypesOf(hello))
+		count++
+	}
+	if sig.Groups != nil {
+		total += orderedSimilarity(sig.Groups, groupsOf(hello))
+		count++
+	}
+	if sig.SignatureAlgorithms != nil {
+		total += orderedSimilarity(sig.SignatureAlgorithms, signatureAlgorithmsOf(hello))
+		count++
+	}
+	if sig.CertificateCompression != nil {
+		total += orderedSimilarity(sig.CertificateCompression, certificateCompressionOf(hello))
+		count++
+	}
+	if sig.DelegatedCredentials != nil {
+		total += orderedSimilarity(sig.DelegatedCredentials, delegatedCredentialsOf(hello))
+		count++
+	}
+	if sig.ApplicationSettings != nil {
+		total += orderedSimilarity(sig.ApplicationSettings, applicationSettingsOf(hello))
+		count++
+	}
+	if sig.RecordSizeLimit != 0 {
+		if sig.RecordSizeLimit == recordSizeLimitOf(hello) {
+			total++
+		}
+		count++
+	}
+	if count == 0 {
+		return 0, false
+	}
+	similarity := total / float64(count)
+	if sig.Grease != nil && *sig.Grease != sendsGrease(hello) {
+		similarity *= 0.5
+	}
+	return similarity, true
+}
+
+func (sig *ClientSignature) match(hello *ClientHelloInfo) (Match, bool) {
+	m := Match{Product: sig.Product, Versions: sig.Versions}
+	if slices.Contains(sig.JA4, hello.Info.JA4Fingerprint) {
+		m.Confidence, m.Reason = ja4Confidence, "ja4"
+	} else if slices.Contains(sig.JA3, hello.Info.JA3Fingerprint) {
+		m.Confidence, m.Reason = ja3Confidence, "ja3"
+	} else if similarity, ok := sig.structuralSimilarity(hello); ok {
+		m.Confidence, m.Reason = structureConfidence*similarity, "structure"
+	}
+	return m, m.Confidence >= minimumConfidence
+}
+
+// Identify returns the clients in the database which plausibly sent hello, most likely first
+func (db *Database) Identify(hello *ClientHelloInfo) []Match {
+	matches := []Match{}
+	for i := range db.Clients {
+		if m, ok := db.Clients[i].match(hello); ok {
+			matches = append(matches, m)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Confidence > matches[j].Confidence })
+	return matches
+}
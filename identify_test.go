@@ -0,0 +1,83 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package tlshacks
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// readTestHello parses a ClientHello handshake message stored as hex in testdata
+func readTestHello(t *testing.T, name string) *ClientHelloInfo {
+	t.Helper()
+	hexBytes, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := hex.DecodeString(strings.TrimSpace(string(hexBytes)))
+	if err != nil {
+		t.Fatalf("%s: %s", name, err)
+	}
+	info := UnmarshalClientHello(raw)
+	if info == nil {
+		t.Fatalf("%s: unable to parse ClientHello", name)
+	}
+	return info
+}
+
+func TestIdentifyBrowsers(t *testing.T) {
+	tests := []struct {
+		file   string
+		client string
+	}{
+		{"chrome-131.hex", "Chrome 131-132"},
+		{"chrome-133.hex", "Chrome 133+"},
+		{"firefox-128.hex", "Firefox 128+"},
+	}
+	for _, test := range tests {
+		info := readTestHello(t, test.file)
+		if info.Info.Client != test.client {
+			t.Errorf("%s: identified as %q, expected %q (matches: %v)", test.file, info.Info.Client, test.client, info.Info.ClientMatches)
+		}
+	}
+}
+
+// TestIdentifyPermutedExtensions checks that Chrome is still identified when it
+// sends its extensions in a different order, which changes its JA3 fingerprint
+func TestIdentifyPermutedExtensions(t *testing.T) {
+	info := readTestHello(t, "chrome-133.hex")
+	extensions := info.Extensions
+	for i, j := 0, len(extensions)-1; i < j; i, j = i+1, j-1 {
+		extensions[i], extensions[j] = extensions[j], extensions[i]
+	}
+	info.populateInfo()
+	if info.Info.Client != "Chrome 133+" {
+		t.Errorf("identified as %q, expected %q (matches: %v)", info.Info.Client, "Chrome 133+", info.Info.ClientMatches)
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+// Package connlog logs one structured record for every connection accepted by a tlshacks
+// listener: from http.Server.ConnState for connections which reach the server, and from
+// a listener hook for connections whose ClientHello can't be read.
+package connlog
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"src.agwa.name/tlshacks"
+)
+
+type Logger struct {
+	logger *slog.Logger
+}
+
+func New(logger *slog.Logger) *Logger {
+	return &Logger{logger: logger}
+}
+
+// ConnState logs the connection when it's closed.  It should be used as (or called from)
+// http.Server.ConnState.
+func (l *Logger) ConnState(conn net.Conn, state http.ConnState) {
+	if state != http.StateClosed && state != http.StateHijacked {
+		return
+	}
+	tlsConn, ok := conn.(*tls.Conn)
+	if !ok {
+		return
+	}
+	attrs := []slog.Attr{slog.String("remote_addr", conn.RemoteAddr().String())}
+	if tlshacksConn, ok := tlsConn.NetConn().(*tlshacks.Conn); ok {
+		attrs = append(attrs, clientHelloAttrs(tlshacksConn.ClientHello)...)
+	}
+
+	connState := tlsConn.ConnectionState()
+	if !connState.HandshakeComplete {
+		// Handshake returns the error from the failed handshake
+		if err := tlsConn.Handshake(); err != nil {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+		l.logger.LogAttrs(context.Background(), slog.LevelWarn, "handshake failed", attrs...)
+		return
+	}
+	attrs = append(attrs,
+		slog.String("version", tls.VersionName(connState.Version)),
+		slog.String("cipher_suite", tls.CipherSuiteName(connState.CipherSuite)),
+		slog.String("alpn", connState.NegotiatedProtocol),
+		slog.Bool("resumed", connState.DidResume),
+	)
+	if len(connState.PeerCertificates) > 0 {
+		fingerprints := make([]string, len(connState.PeerCertificates))
+		for i, cert := range connState.PeerCertificates {
+			fingerprint := sha256.Sum256(cert.Raw)
+			fingerprints[i] = hex.EncodeToString(fingerprint[:])
+		}
+		attrs = append(attrs, slog.Any("client_certificates", fingerprints))
+	}
+	l.logger.LogAttrs(context.Background(), slog.LevelInfo, "connection", attrs...)
+}
+
+// Hooks returns listener hooks which log connections whose ClientHello can't be read (e.g.
+// because the client timed out or didn't speak TLS).  The listener closes such connections
+// without passing them to the http.Server, so ConnState never sees them.
+func (l *Logger) Hooks() *tlshacks.ListenerHooks {
+	return &tlshacks.ListenerHooks{
+		Error: func(conn net.Conn, err error, elapsed time.Duration) {
+			l.logger.LogAttrs(context.Background(), slog.LevelWarn, "handshake failed",
+				slog.String("remote_addr", conn.RemoteAddr().String()),
+				slog.String("error", "reading ClientHello: "+err.Error()),
+			)
+		},
+	}
+}
+
+func clientHelloAttrs(clientHello []byte) []slog.Attr {
+	info := tlshacks.UnmarshalClientHello(clientHello)
+	if info == nil {
+		return []slog.Attr{slog.Bool("malformed_client_hello", true)}
+	}
+	var serverName string
+	if info.Info.ServerName != nil {
+		serverName = *info.Info.ServerName
+	}
+	protocols := info.Info.Protocols
+	if protocols == nil {
+		protocols = []string{}
+	}
+	return []slog.Attr{
+		slog.String("sni", serverName),
+		slog.Any("alpn_offered", protocols),
+		slog.String("ja3", info.Info.JA3Fingerprint),
+		slog.String("ja4", info.Info.JA4Fingerprint),
+		slog.String("resumption_offered", info.Info.Resumption),
+	}
+}
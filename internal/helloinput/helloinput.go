@@ -0,0 +1,160 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+// Package helloinput decodes ClientHellos supplied by users on the command line.
+package helloinput
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"src.agwa.name/tlshacks"
+)
+
+// Formats describes the formats accepted by Decode, for use in flag help
+const Formats = "auto, hex, base64, binary, json, or pem"
+
+const (
+	recordTypeHandshake      = 22
+	handshakeTypeClientHello = 1
+)
+
+func isHexString(str string) bool {
+	for _, c := range str {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return false
+		}
+	}
+	return len(str)%2 == 0
+}
+
+func removeSpaces(str string) string {
+	return strings.Map(func(c rune) rune {
+		if strings.ContainsRune(" \t\r\n:", c) {
+			return -1
+		}
+		return c
+	}, str)
+}
+
+// decodeInput converts the hex, base64, or binary input into binary.  As a special case,
+// the JSON output of tlshellohttpd is accepted, in which case the "raw" field is used,
+// as is the PEM output of tlshellohttpd's /pem endpoint.
+func decodeInput(input []byte, format string) ([]byte, error) {
+	if format == "auto" {
+		trimmed := bytes.TrimSpace(input)
+		if bytes.HasPrefix(trimmed, []byte("{")) {
+			format = "json"
+		} else if bytes.HasPrefix(trimmed, []byte("-----BEGIN ")) {
+			format = "pem"
+		} else if isHexString(removeSpaces(string(trimmed))) {
+			format = "hex"
+		} else if _, err := base64.StdEncoding.DecodeString(removeSpaces(string(trimmed))); err == nil {
+			format = "base64"
+		} else {
+			format = "binary"
+		}
+	}
+
+	switch format {
+	case "json":
+		var output struct {
+			Raw []byte `json:"raw"`
+		}
+		if err := json.Unmarshal(input, &output); err != nil {
+			return nil, fmt.Errorf("error parsing JSON: %w", err)
+		}
+		return output.Raw, nil
+	case "pem":
+		block, _ := pem.Decode(input)
+		if block == nil {
+			return nil, errors.New("error parsing PEM: no PEM block found")
+		}
+		return block.Bytes, nil
+	case "hex":
+		return hex.DecodeString(removeSpaces(string(input)))
+	case "base64":
+		return base64.StdEncoding.DecodeString(removeSpaces(string(input)))
+	case "binary":
+		return input, nil
+	default:
+		return nil, fmt.Errorf("unknown input format %q", format)
+	}
+}
+
+// handshakeMessage returns the first handshake message in data, which contains either
+// a handshake message or TLS records
+func handshakeMessage(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, errors.New("input is empty")
+	}
+	switch data[0] {
+	case recordTypeHandshake:
+		return tlshacks.NewHandshakeReader(bytes.NewReader(data)).ReadMessage()
+	case handshakeTypeClientHello:
+		return data, nil
+	default:
+		return nil, fmt.Errorf("input is neither a TLS record nor a ClientHello message (first byte is 0x%02x)", data[0])
+	}
+}
+
+// ReadFile reads the named file, or standard input if filename is "-"
+func ReadFile(filename string) ([]byte, error) {
+	if filename == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(filename)
+}
+
+// Decode returns the handshake message contained in input, which is
+// in one of the formats described by Formats
+func Decode(input []byte, format string) ([]byte, error) {
+	data, err := decodeInput(input, format)
+	if err != nil {
+		return nil, err
+	}
+	return handshakeMessage(data)
+}
+
+// DecodeClientHello is like Decode, but parses the ClientHello
+func DecodeClientHello(input []byte, format string) (*tlshacks.ClientHelloInfo, error) {
+	message, err := Decode(input, format)
+	if err != nil {
+		return nil, err
+	}
+	info := tlshacks.UnmarshalClientHello(message)
+	if info == nil {
+		return nil, errors.New("malformed ClientHello")
+	}
+	return info, nil
+}
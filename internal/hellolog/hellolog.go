@@ -0,0 +1,288 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+// Package hellolog stores received ClientHellos in an append-only file.
+//
+// The file contains one JSON-encoded Record per line, so it can also be processed
+// with standard tools.  When the file is opened, it is scanned to build an in-memory
+// index of each record's offset, timestamp, and fingerprints, which is used to answer
+// queries without reading records that don't match.
+package hellolog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Record struct {
+	Time       time.Time `json:"time"`
+	RemoteAddr string    `json:"remote_addr"`
+	ServerName string    `json:"server_name,omitempty"`
+	JA3        string    `json:"ja3"` // fingerprint (hash)
+	JA4        string    `json:"ja4"` // fingerprint
+	Raw        []byte    `json:"raw"`
+}
+
+type indexEntry struct {
+	offset int64
+	length int
+	time   time.Time
+	ja3    string
+	ja4    string
+}
+
+type Log struct {
+	mu      sync.RWMutex
+	file    *os.File
+	size    int64
+	entries []indexEntry
+	byJA3   map[string][]int // indexes into entries, in order
+	byJA4   map[string][]int // indexes into entries, in order
+}
+
+// Open opens the log file at path, creating it if it doesn't exist.  If the file ends
+// with an incomplete record (e.g. because of a crash during Append), the incomplete
+// record is removed.
+func Open(path string) (*Log, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0666)
+	if err != nil {
+		return nil, err
+	}
+	log := &Log{
+		file:  file,
+		byJA3: make(map[string][]int),
+		byJA4: make(map[string][]int),
+	}
+	if err := log.load(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return log, nil
+}
+
+func (log *Log) load() error {
+	reader := bufio.NewReader(log.file)
+	var offset int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		var record Record
+		if err := json.Unmarshal(line, &record); err != nil {
+			return fmt.Errorf("malformed record at offset %d: %w", offset, err)
+		}
+		log.index(offset, len(line), &record)
+		offset += int64(len(line))
+	}
+	if err := log.file.Truncate(offset); err != nil {
+		return err
+	}
+	log.size = offset
+	return nil
+}
+
+func (log *Log) index(offset int64, length int, record *Record) {
+	i := len(log.entries)
+	log.entries = append(log.entries, indexEntry{
+		offset: offset,
+		length: length,
+		time:   record.Time,
+		ja3:    record.JA3,
+		ja4:    record.JA4,
+	})
+	log.byJA3[record.JA3] = append(log.byJA3[record.JA3], i)
+	log.byJA4[record.JA4] = append(log.byJA4[record.JA4], i)
+}
+
+// Append adds record to the end of the log
+func (log *Log) Append(record *Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	if _, err := log.file.WriteAt(line, log.size); err != nil {
+		// Discard what may have been partially written, so the next record starts on a new line
+		log.file.Truncate(log.size)
+		return err
+	}
+	log.index(log.size, len(line), record)
+	log.size += int64(len(line))
+	return nil
+}
+
+func (log *Log) Close() error {
+	return log.file.Close()
+}
+
+type Query struct {
+	JA3   string    // only return records with this JA3 fingerprint (if non-empty)
+	JA4   string    // only return records with this JA4 fingerprint (if non-empty)
+	Since time.Time // only return records at or after this time (if non-zero)
+	Until time.Time // only return records before this time (if non-zero)
+	Limit int       // maximum number of records to return (if non-zero)
+}
+
+func (query *Query) matches(entry *indexEntry) bool {
+	return (query.JA3 == "" || entry.ja3 == query.JA3) &&
+		(query.JA4 == "" || entry.ja4 == query.JA4) &&
+		(query.Since.IsZero() || !entry.time.Before(query.Since)) &&
+		(query.Until.IsZero() || entry.time.Before(query.Until))
+}
+
+// candidates returns the indexes of the entries which might match the query, in order
+func (log *Log) candidates(query *Query) []int {
+	if query.JA4 != "" && (query.JA3 == "" || len(log.byJA4[query.JA4]) <= len(log.byJA3[query.JA3])) {
+		return log.byJA4[query.JA4]
+	} else if query.JA3 != "" {
+		return log.byJA3[query.JA3]
+	}
+	all := make([]int, len(log.entries))
+	for i := range all {
+		all[i] = i
+	}
+	return all
+}
+
+// matching returns the index entries matching the query, most recent first
+func (log *Log) matching(query *Query) []indexEntry {
+	log.mu.RLock()
+	defer log.mu.RUnlock()
+
+	var entries []indexEntry
+	for _, i := range slices.Backward(log.candidates(query)) {
+		if query.Limit != 0 && len(entries) >= query.Limit {
+			break
+		}
+		if entry := &log.entries[i]; query.matches(entry) {
+			entries = append(entries, *entry)
+		}
+	}
+	return entries
+}
+
+// Query returns the records matching the query, most recent first.  The records are
+// read from the file without holding the lock, since indexed records are never modified.
+func (log *Log) Query(query Query) ([]Record, error) {
+	records := []Record{}
+	for _, entry := range log.matching(&query) {
+		line := make([]byte, entry.length)
+		if _, err := log.file.ReadAt(line, entry.offset); err != nil {
+			return nil, err
+		}
+		var record Record
+		if err := json.Unmarshal(bytes.TrimSuffix(line, []byte("\n")), &record); err != nil {
+			return nil, fmt.Errorf("malformed record at offset %d: %w", entry.offset, err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+type FingerprintCount struct {
+	Fingerprint string    `json:"fingerprint"`
+	Count       int       `json:"count"`
+	FirstSeen   time.Time `json:"first_seen"`
+	LastSeen    time.Time `json:"last_seen"`
+}
+
+type Stats struct {
+	Total int                `json:"total"`
+	JA3   []FingerprintCount `json:"ja3"` // most common first
+	JA4   []FingerprintCount `json:"ja4"` // most common first
+}
+
+// Stats counts the records at or after since (or all records, if since is zero) by fingerprint
+func (log *Log) Stats(since time.Time) *Stats {
+	log.mu.RLock()
+	defer log.mu.RUnlock()
+
+	ja3 := make(map[string]*FingerprintCount)
+	ja4 := make(map[string]*FingerprintCount)
+	stats := &Stats{JA3: []FingerprintCount{}, JA4: []FingerprintCount{}}
+	for i := range log.entries {
+		entry := &log.entries[i]
+		if !since.IsZero() && entry.time.Before(since) {
+			continue
+		}
+		stats.Total++
+		count(ja3, entry.ja3, entry.time)
+		count(ja4, entry.ja4, entry.time)
+	}
+	stats.JA3 = sortedCounts(ja3)
+	stats.JA4 = sortedCounts(ja4)
+	return stats
+}
+
+func count(counts map[string]*FingerprintCount, fingerprint string, t time.Time) {
+	c := counts[fingerprint]
+	if c == nil {
+		c = &FingerprintCount{Fingerprint: fingerprint, FirstSeen: t, LastSeen: t}
+		counts[fingerprint] = c
+	}
+	c.Count++
+	if t.Before(c.FirstSeen) {
+		c.FirstSeen = t
+	}
+	if t.After(c.LastSeen) {
+		c.LastSeen = t
+	}
+}
+
+func sortedCounts(counts map[string]*FingerprintCount) []FingerprintCount {
+	sorted := make([]FingerprintCount, 0, len(counts))
+	for _, c := range counts {
+		sorted = append(sorted, *c)
+	}
+	slices.SortFunc(sorted, func(a, b FingerprintCount) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return strings.Compare(a.Fingerprint, b.Fingerprint)
+	})
+	return sorted
+}
+
+// ParseTime parses a time given either in RFC 3339 format or as a duration before now (e.g. "24h")
+func ParseTime(str string, now time.Time) (time.Time, error) {
+	if duration, err := time.ParseDuration(str); err == nil {
+		return now.Add(-duration), nil
+	}
+	return time.Parse(time.RFC3339, str)
+}
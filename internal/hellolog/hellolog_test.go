@@ -0,0 +1,263 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package hellolog
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+var testTime = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// testRecords returns records one minute apart, with the given JA3 and JA4 fingerprints
+func testRecords(ja3 []string, ja4 []string) []Record {
+	records := make([]Record, len(ja3))
+	for i := range records {
+		records[i] = Record{
+			Time:       testTime.Add(time.Duration(i) * time.Minute),
+			RemoteAddr: fmt.Sprintf("192.0.2.%d:443", i),
+			ServerName: "example.com",
+			JA3:        ja3[i],
+			JA4:        ja4[i],
+			Raw:        []byte{1, 0, 0, byte(i)},
+		}
+	}
+	return records
+}
+
+func openTestLog(t *testing.T, path string, records []Record) *Log {
+	t.Helper()
+	log, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range records {
+		if err := log.Append(&records[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return log
+}
+
+func checkRecords(t *testing.T, context string, got []Record, expected []Record) {
+	t.Helper()
+	if len(got) != len(expected) {
+		t.Errorf("%s: %d records returned, expected %d", context, len(got), len(expected))
+		return
+	}
+	for i := range got {
+		if !got[i].Time.Equal(expected[i].Time) || got[i].RemoteAddr != expected[i].RemoteAddr || got[i].ServerName != expected[i].ServerName ||
+			got[i].JA3 != expected[i].JA3 || got[i].JA4 != expected[i].JA4 || !bytes.Equal(got[i].Raw, expected[i].Raw) {
+			t.Errorf("%s: record %d is %+v, expected %+v", context, i, got[i], expected[i])
+		}
+	}
+}
+
+func TestReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	records := testRecords([]string{"a", "b", "a"}, []string{"x", "y", "x"})
+
+	log := openTestLog(t, path, records[:2])
+	if err := log.Close(); err != nil {
+		t.Fatal(err)
+	}
+	log = openTestLog(t, path, records[2:])
+	if err := log.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	log, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+	all, err := log.Query(Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRecords(t, "reopened log", all, []Record{records[2], records[1], records[0]})
+	byJA3, err := log.Query(Query{JA3: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRecords(t, "reopened log queried by JA3", byJA3, []Record{records[2], records[0]})
+}
+
+func TestOpenTruncatesPartialRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	records := testRecords([]string{"a", "b", "c"}, []string{"x", "y", "z"})
+
+	log := openTestLog(t, path, records[:2])
+	log.Close()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(`{"time":"2026-01-02T03:`) // as if the process crashed while appending
+	file.Close()
+
+	log = openTestLog(t, path, nil)
+	if truncated, err := os.Stat(path); err != nil {
+		t.Fatal(err)
+	} else if truncated.Size() != info.Size() {
+		t.Errorf("log is %d bytes after Open, expected the partial record to be truncated to %d bytes", truncated.Size(), info.Size())
+	}
+	if err := log.Append(&records[2]); err != nil {
+		t.Fatal(err)
+	}
+	log.Close()
+
+	log = openTestLog(t, path, nil)
+	defer log.Close()
+	all, err := log.Query(Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRecords(t, "log after truncation", all, []Record{records[2], records[1], records[0]})
+}
+
+func TestOpenMalformed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	if err := os.WriteFile(path, []byte("{\"time\":\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if log, err := Open(path); err == nil {
+		log.Close()
+		t.Error("Open succeeded on a log with a malformed complete record")
+	}
+}
+
+func TestQuery(t *testing.T) {
+	records := testRecords(
+		[]string{"a", "a", "b", "a", "b", "a"},
+		[]string{"x", "y", "x", "x", "y", "x"},
+	)
+	log := openTestLog(t, filepath.Join(t.TempDir(), "log"), records)
+	defer log.Close()
+
+	minute := func(i int) time.Time { return testTime.Add(time.Duration(i) * time.Minute) }
+	tests := []struct {
+		query    Query
+		expected []int // indexes into records, most recent first
+	}{
+		{Query{}, []int{5, 4, 3, 2, 1, 0}},
+		{Query{Limit: 2}, []int{5, 4}},
+		{Query{JA3: "a"}, []int{5, 3, 1, 0}},
+		{Query{JA4: "y"}, []int{4, 1}},
+		{Query{JA3: "a", JA4: "x"}, []int{5, 3, 0}},
+		{Query{JA3: "a", JA4: "y"}, []int{1}}, // selects the smaller JA4 index
+		{Query{JA3: "b", JA4: "x"}, []int{2}}, // selects the smaller JA3 index
+		{Query{JA3: "c"}, []int{}},
+		{Query{JA3: "a", JA4: "z"}, []int{}},
+		{Query{Since: minute(2)}, []int{5, 4, 3, 2}},
+		{Query{Until: minute(2)}, []int{1, 0}},
+		{Query{Since: minute(1), Until: minute(4)}, []int{3, 2, 1}},
+		{Query{JA3: "a", Since: minute(1), Until: minute(4)}, []int{3, 1}},
+		{Query{JA3: "a", Until: minute(5), Limit: 2}, []int{3, 1}}, // the limit counts matching records only
+		{Query{JA4: "x", Since: minute(1), Limit: 10}, []int{5, 3, 2}},
+	}
+	for _, test := range tests {
+		got, err := log.Query(test.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := make([]Record, len(test.expected))
+		for i, index := range test.expected {
+			expected[i] = records[index]
+		}
+		checkRecords(t, fmt.Sprintf("%+v", test.query), got, expected)
+	}
+
+	candidates := []struct {
+		query    Query
+		expected []int
+	}{
+		{Query{JA3: "a", JA4: "y"}, log.byJA4["y"]},
+		{Query{JA3: "b", JA4: "x"}, log.byJA3["b"]},
+		{Query{JA3: "a"}, log.byJA3["a"]},
+		{Query{JA4: "x"}, log.byJA4["x"]},
+		{Query{Since: minute(3)}, []int{0, 1, 2, 3, 4, 5}},
+	}
+	for _, test := range candidates {
+		if got := log.candidates(&test.query); !slices.Equal(got, test.expected) {
+			t.Errorf("%+v: candidates are %v, expected %v", test.query, got, test.expected)
+		}
+	}
+}
+
+func TestStats(t *testing.T) {
+	records := testRecords(
+		[]string{"b", "a", "b", "a", "c", "a"},
+		[]string{"x", "y", "x", "x", "y", "z"},
+	)
+	log := openTestLog(t, filepath.Join(t.TempDir(), "log"), records)
+	defer log.Close()
+	minute := func(i int) time.Time { return testTime.Add(time.Duration(i) * time.Minute) }
+
+	tests := []struct {
+		since time.Time
+		total int
+		ja3   []FingerprintCount
+		ja4   []FingerprintCount
+	}{
+		{
+			time.Time{}, 6,
+			[]FingerprintCount{{"a", 3, minute(1), minute(5)}, {"b", 2, minute(0), minute(2)}, {"c", 1, minute(4), minute(4)}},
+			[]FingerprintCount{{"x", 3, minute(0), minute(3)}, {"y", 2, minute(1), minute(4)}, {"z", 1, minute(5), minute(5)}},
+		},
+		{
+			minute(3), 3,
+			[]FingerprintCount{{"a", 2, minute(3), minute(5)}, {"c", 1, minute(4), minute(4)}},
+			[]FingerprintCount{{"x", 1, minute(3), minute(3)}, {"y", 1, minute(4), minute(4)}, {"z", 1, minute(5), minute(5)}}, // ties are ordered by fingerprint
+		},
+		{
+			minute(10), 0,
+			[]FingerprintCount{},
+			[]FingerprintCount{},
+		},
+	}
+	for _, test := range tests {
+		stats := log.Stats(test.since)
+		if stats.Total != test.total {
+			t.Errorf("since %s: total is %d, expected %d", test.since, stats.Total, test.total)
+		}
+		if !slices.Equal(stats.JA3, test.ja3) {
+			t.Errorf("since %s: JA3 counts are %v, expected %v", test.since, stats.JA3, test.ja3)
+		}
+		if !slices.Equal(stats.JA4, test.ja4) {
+			t.Errorf("since %s: JA4 counts are %v, expected %v", test.since, stats.JA4, test.ja4)
+		}
+	}
+}
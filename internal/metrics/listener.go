@@ -0,0 +1,131 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"time"
+
+	"src.agwa.name/tlshacks"
+)
+
+// Limits on the number of distinct label values, to bound the number of time series
+// which clients can cause to be created
+const (
+	maxJA4s      = 200
+	maxProtocols = 50
+	maxVersions  = 20
+)
+
+// ListenerMetrics counts the connections accepted by a tlshacks listener and the ClientHellos
+// received on them.  Its Hooks can be shared by multiple listeners.
+type ListenerMetrics struct {
+	accepted    *Counter
+	failures    *CounterVec
+	readSeconds *Histogram
+	helloBytes  *Histogram
+	ja4s        *CounterVec
+	protocols   *CounterVec
+	versions    *CounterVec
+}
+
+func NewListenerMetrics(r *Registry) *ListenerMetrics {
+	return &ListenerMetrics{
+		accepted:    r.NewCounter("tlshacks_connections_accepted_total", "Connections accepted."),
+		failures:    r.NewCounterVec("tlshacks_hello_failures_total", "Connections whose ClientHello could not be read or parsed, by reason.", "reason", 10),
+		readSeconds: r.NewHistogram("tlshacks_hello_read_seconds", "Time taken to read the ClientHello.", []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 2.5, 5}),
+		helloBytes:  r.NewHistogram("tlshacks_hello_size_bytes", "Size of the ClientHello handshake message.", []float64{128, 256, 512, 1024, 2048, 4096, 8192, 16384}),
+		ja4s:        r.NewCounterVec("tlshacks_hello_ja4_total", "ClientHellos received, by JA4 fingerprint.", "ja4", maxJA4s),
+		protocols:   r.NewCounterVec("tlshacks_hello_alpn_offers_total", "ALPN protocols offered in ClientHellos.", "protocol", maxProtocols),
+		versions:    r.NewCounterVec("tlshacks_hello_version_offers_total", "TLS versions offered in ClientHellos.", "version", maxVersions),
+	}
+}
+
+// failureReason classifies an error from reading the ClientHello
+func failureReason(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, os.ErrDeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return "eof"
+	default:
+		return "read_error"
+	}
+}
+
+// offeredVersions returns the versions in supported_versions, or legacy_version if it's absent
+func offeredVersions(info *tlshacks.ClientHelloInfo) []tlshacks.ProtocolVersion {
+	for _, ext := range info.Extensions {
+		if data, ok := ext.Data.(*tlshacks.SupportedVersionsData); ok && data.Valid {
+			return data.Versions
+		}
+	}
+	return []tlshacks.ProtocolVersion{info.Version}
+}
+
+// versionLabel returns the label for an offered version.  GREASE values share a single
+// label, so that they don't use up the maxVersions label values.
+func versionLabel(version tlshacks.ProtocolVersion) string {
+	if v := uint16(version); v&0x0f0f == 0x0a0a && v>>8 == v&0xff {
+		return "GREASE"
+	}
+	return version.String()
+}
+
+func (m *ListenerMetrics) clientHello(conn *tlshacks.Conn, elapsed time.Duration) {
+	m.readSeconds.Observe(elapsed.Seconds())
+	m.helloBytes.Observe(float64(len(conn.ClientHello)))
+	if conn.ClientHello[0] != 1 {
+		m.failures.Inc("not_client_hello")
+		return
+	}
+	info := tlshacks.UnmarshalClientHello(conn.ClientHello)
+	if info == nil {
+		m.failures.Inc("malformed")
+		return
+	}
+	m.ja4s.Inc(info.Info.JA4Fingerprint)
+	for _, protocol := range info.Info.Protocols {
+		m.protocols.Inc(protocol)
+	}
+	for _, version := range offeredVersions(info) {
+		m.versions.Inc(versionLabel(version))
+	}
+}
+
+func (m *ListenerMetrics) Hooks() *tlshacks.ListenerHooks {
+	return &tlshacks.ListenerHooks{
+		Accepted:    func(net.Conn) { m.accepted.Inc() },
+		ClientHello: m.clientHello,
+		Error: func(conn net.Conn, err error, elapsed time.Duration) {
+			m.failures.Inc(failureReason(err))
+		},
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+// Package negotiate chooses the format of an HTTP response.
+package negotiate
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var mediaTypes = map[string]string{
+	"json": "application/json",
+	"html": "text/html",
+	"text": "text/plain",
+}
+
+// acceptQuality returns the quality value which the Accept header assigns to exactly the given media type
+func acceptQuality(accept string, mediaType string) float64 {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		params := strings.Split(mediaRange, ";")
+		if !strings.EqualFold(strings.TrimSpace(params[0]), mediaType) {
+			continue
+		}
+		quality := 1.0
+		for _, param := range params[1:] {
+			if name, value, ok := strings.Cut(strings.TrimSpace(param), "="); ok && name == "q" {
+				if q, err := strconv.ParseFloat(value, 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		return quality
+	}
+	return 0
+}
+
+// Format returns which of formats ("json", "html", or "text") should be used for the response
+// to req.  The format query parameter takes precedence; otherwise, a format is used only if the
+// Accept header explicitly prefers its media type, so clients which send */* (e.g. curl) get
+// formats[0], the default.
+func Format(req *http.Request, formats ...string) string {
+	if format := req.URL.Query().Get("format"); slices.Contains(formats, format) {
+		return format
+	}
+	accept := req.Header.Get("Accept")
+	best, bestQuality := formats[0], acceptQuality(accept, mediaTypes[formats[0]])
+	for _, format := range formats[1:] {
+		if quality := acceptQuality(accept, mediaTypes[format]); quality > bestQuality {
+			best, bestQuality = format, quality
+		}
+	}
+	return best
+}
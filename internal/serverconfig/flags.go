@@ -0,0 +1,147 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package serverconfig
+
+import (
+	"flag"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Flags holds settings from the command line, which take precedence over the configuration file
+type Flags struct {
+	configFile string
+	overrides  []func(*Config)
+}
+
+func splitList(value string) []string {
+	if value == "" {
+		return []string{}
+	}
+	return strings.Split(value, ",")
+}
+
+// NewFlags registers the -config flag, along with flags for the logging settings and
+// for the defaults which apply to every server
+func NewFlags(fs *flag.FlagSet) *Flags {
+	f := new(Flags)
+	fs.StringVar(&f.configFile, "config", "", "Path to JSON configuration file")
+	fs.BoolFunc("verbose", "Log every connection as JSON", func(value string) error {
+		verbose, err := strconv.ParseBool(value)
+		f.Set(func(config *Config) { config.Verbose = verbose })
+		return err
+	})
+	fs.Func("log-file", "Write log to this file instead of standard error", func(value string) error {
+		f.Set(func(config *Config) { config.LogFile = value })
+		return nil
+	})
+	fs.Func("admin-listen", "Socket for the plain HTTP admin listener, which serves /metrics and other private endpoints", func(value string) error {
+		f.Set(func(config *Config) { config.AdminListen = value })
+		return nil
+	})
+	fs.Func("alpn", "Comma-separated list of ALPN protocols to advertise (default h2,http/1.1)", func(value string) error {
+		f.Set(func(config *Config) { config.Defaults.ALPN = splitList(value) })
+		return nil
+	})
+	fs.Func("min-version", "Minimum TLS version (1.0, 1.1, 1.2, or 1.3)", func(value string) error {
+		_, err := parseVersion(value)
+		f.Set(func(config *Config) { config.Defaults.MinVersion = value })
+		return err
+	})
+	fs.Func("max-version", "Maximum TLS version (1.0, 1.1, 1.2, or 1.3)", func(value string) error {
+		_, err := parseVersion(value)
+		f.Set(func(config *Config) { config.Defaults.MaxVersion = value })
+		return err
+	})
+	fs.Func("cipher-suites", "Comma-separated list of TLS 1.2 and below cipher suite names", func(value string) error {
+		suites := splitList(value)
+		for _, name := range suites {
+			if _, err := parseCipherSuite(name); err != nil {
+				return err
+			}
+		}
+		f.Set(func(config *Config) { config.Defaults.CipherSuites = suites })
+		return nil
+	})
+	durationFlag := func(name string, usage string, field func(*Server) *Duration) {
+		fs.Func(name, usage, func(value string) error {
+			duration, err := time.ParseDuration(value)
+			f.Set(func(config *Config) { *field(&config.Defaults) = Duration(duration) })
+			return err
+		})
+	}
+	durationFlag("read-timeout", "HTTP read timeout (default 5s)", func(s *Server) *Duration { return &s.ReadTimeout })
+	durationFlag("write-timeout", "HTTP write timeout (default 10s)", func(s *Server) *Duration { return &s.WriteTimeout })
+	durationFlag("idle-timeout", "HTTP idle timeout (default 5s)", func(s *Server) *Duration { return &s.IdleTimeout })
+	fs.BoolFunc("keep-alives", "Enable HTTP keep-alives", func(value string) error {
+		keepAlives, err := strconv.ParseBool(value)
+		f.Set(func(config *Config) { config.Defaults.KeepAlives = &keepAlives })
+		return err
+	})
+	return f
+}
+
+// ServerFlags registers the -PREFIXlisten, -PREFIXcert, and -PREFIXendpoints flags for the named server
+func (f *Flags) ServerFlags(fs *flag.FlagSet, name string, prefix string, description string) {
+	fs.Func(prefix+"listen", "Socket for "+description+" to listen on", func(value string) error {
+		f.Set(func(config *Config) { config.server(name).Listen = value })
+		return nil
+	})
+	fs.Func(prefix+"cert", "Hostname or certificate file for "+description, func(value string) error {
+		f.Set(func(config *Config) { config.server(name).Cert = value })
+		return nil
+	})
+	fs.Func(prefix+"endpoints", "Comma-separated list of paths for "+description+" to serve (default all)", func(value string) error {
+		f.Set(func(config *Config) { config.server(name).Endpoints = splitList(value) })
+		return nil
+	})
+}
+
+// SetServer arranges for fn to modify the named server's configuration after the configuration file is loaded
+func (f *Flags) SetServer(name string, fn func(*Server)) {
+	f.Set(func(config *Config) { fn(config.server(name)) })
+}
+
+// Set arranges for fn to modify the configuration after the configuration file is loaded
+func (f *Flags) Set(fn func(*Config)) {
+	f.overrides = append(f.overrides, fn)
+}
+
+// Config loads the configuration file, if one was specified, and applies the command line settings
+func (f *Flags) Config() (*Config, error) {
+	config := new(Config)
+	if f.configFile != "" {
+		var err error
+		if config, err = Load(f.configFile); err != nil {
+			return nil, err
+		}
+	}
+	for _, override := range f.overrides {
+		override(config)
+	}
+	return config, nil
+}
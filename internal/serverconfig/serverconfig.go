@@ -0,0 +1,388 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+// Package serverconfig holds the configuration shared by tlshellohttpd and tlstoolshttpd.
+//
+// A configuration file is JSON, and looks like this:
+//
+//	{
+//		"verbose": true,
+//		"admin_listen": "tcp:127.0.0.1:9100",
+//		"defaults": {
+//			"alpn": ["h2", "http/1.1"],
+//			"min_version": "1.2",
+//			"read_timeout": "5s"
+//		},
+//		"servers": {
+//			"tlshellohttpd": {
+//				"listen": "tcp:443",
+//				"cert": "/etc/tlshacks/cert.pem",
+//				"endpoints": ["/", "/ja3", "/ja4"],
+//				"hello_log": "/var/lib/tlshacks/hellos.log"
+//			}
+//		}
+//	}
+//
+// Each command reads only the servers it knows about, so one file can configure both commands.
+// Endpoints which reveal information about other clients, such as metrics and the hello log API,
+// are only served on the admin listener, which uses plain HTTP and is disabled by default.
+// Settings which are omitted from a server are taken from "defaults", and then from
+// the built-in defaults.
+package serverconfig
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/acme"
+	"src.agwa.name/go-listener"
+	"src.agwa.name/go-listener/cert"
+	"src.agwa.name/tlshacks"
+)
+
+type Config struct {
+	Verbose     bool               `json:"verbose"`      // log every connection as JSON
+	LogFile     string             `json:"log_file"`     // defaults to standard error
+	AdminListen string             `json:"admin_listen"` // go-listener spec for the plain HTTP admin listener
+	Defaults    Server             `json:"defaults"`
+	Servers     map[string]*Server `json:"servers"`
+}
+
+// Server configures one listener.  Zero values mean "use the default".
+type Server struct {
+	Listen       string   `json:"listen"`        // go-listener spec
+	Cert         string   `json:"cert"`          // path to certificate file (starting with /), or hostname for ACME
+	ALPN         []string `json:"alpn"`          // acme-tls/1 is always added
+	MinVersion   string   `json:"min_version"`   // "1.0", "1.1", "1.2", or "1.3"
+	MaxVersion   string   `json:"max_version"`   // "1.0", "1.1", "1.2", or "1.3"
+	CipherSuites []string `json:"cipher_suites"` // names as in crypto/tls; only affects TLS 1.2 and below
+	ReadTimeout  Duration `json:"read_timeout"`
+	WriteTimeout Duration `json:"write_timeout"`
+	IdleTimeout  Duration `json:"idle_timeout"`
+	KeepAlives   *bool    `json:"keep_alives"`
+	Endpoints    []string `json:"endpoints"` // paths to serve; empty means all of them
+	HelloLog     string   `json:"hello_log"` // file in which to store received ClientHellos (tlshellohttpd only); queried on the admin listener
+	CABundle     string   `json:"ca_bundle"` // PEM file of CAs for verifying client certificates (tlstoolshttpd clientcert only)
+
+	// The following only apply to tlstoolshttpd's clientcert servers
+	ClientAuth          string   `json:"client_auth"`          // "request", "require" (the default), "verify", or "verify_if_given"
+	CAHints             string   `json:"ca_hints"`             // PEM file of CAs to list in CertificateRequest; if empty, the list is empty
+	SignatureAlgorithms []string `json:"signature_algorithms"` // names of signature algorithms which the client's CertificateVerify may use (TLS 1.2 and below only)
+}
+
+// ClientAuthModes are the valid values of Server.ClientAuth
+var ClientAuthModes = []string{"request", "require", "verify", "verify_if_given"}
+
+// Duration is a time.Duration which is represented in JSON as a string like "5s"
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+	duration, err := time.ParseDuration(str)
+	if err != nil {
+		return err
+	}
+	*d = Duration(duration)
+	return nil
+}
+
+var builtinDefaults = Server{
+	ALPN:         []string{"h2", "http/1.1"},
+	ReadTimeout:  Duration(5 * time.Second),
+	WriteTimeout: Duration(10 * time.Second),
+	IdleTimeout:  Duration(5 * time.Second),
+	KeepAlives:   new(bool),
+	ClientAuth:   "require",
+}
+
+// merge fills in the zero fields of s from defaults
+func (s *Server) merge(defaults *Server) {
+	if s.Listen == "" {
+		s.Listen = defaults.Listen
+	}
+	if s.Cert == "" {
+		s.Cert = defaults.Cert
+	}
+	if s.ALPN == nil {
+		s.ALPN = defaults.ALPN
+	}
+	if s.MinVersion == "" {
+		s.MinVersion = defaults.MinVersion
+	}
+	if s.MaxVersion == "" {
+		s.MaxVersion = defaults.MaxVersion
+	}
+	if s.CipherSuites == nil {
+		s.CipherSuites = defaults.CipherSuites
+	}
+	if s.ReadTimeout == 0 {
+		s.ReadTimeout = defaults.ReadTimeout
+	}
+	if s.WriteTimeout == 0 {
+		s.WriteTimeout = defaults.WriteTimeout
+	}
+	if s.IdleTimeout == 0 {
+		s.IdleTimeout = defaults.IdleTimeout
+	}
+	if s.KeepAlives == nil {
+		s.KeepAlives = defaults.KeepAlives
+	}
+	if s.Endpoints == nil {
+		s.Endpoints = defaults.Endpoints
+	}
+	if s.HelloLog == "" {
+		s.HelloLog = defaults.HelloLog
+	}
+	if s.CABundle == "" {
+		s.CABundle = defaults.CABundle
+	}
+	if s.ClientAuth == "" {
+		s.ClientAuth = defaults.ClientAuth
+	}
+	if s.CAHints == "" {
+		s.CAHints = defaults.CAHints
+	}
+	if s.SignatureAlgorithms == nil {
+		s.SignatureAlgorithms = defaults.SignatureAlgorithms
+	}
+}
+
+// Load reads the configuration file at path
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := new(Config)
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return config, nil
+}
+
+// server returns the named server's configuration, creating it if necessary
+func (config *Config) server(name string) *Server {
+	if config.Servers == nil {
+		config.Servers = make(map[string]*Server)
+	}
+	if config.Servers[name] == nil {
+		config.Servers[name] = new(Server)
+	}
+	return config.Servers[name]
+}
+
+// Server returns the named server's configuration with defaults filled in, or nil if the
+// server isn't configured or has no listener
+func (config *Config) Server(name string) *Server {
+	configured := config.Servers[name]
+	if configured == nil {
+		return nil
+	}
+	server := *configured
+	server.merge(&config.Defaults)
+	server.merge(&builtinDefaults)
+	if server.Listen == "" {
+		return nil
+	}
+	return &server
+}
+
+// SetupLogging directs the standard logger to the configured log file
+func (config *Config) SetupLogging() error {
+	if config.LogFile == "" {
+		return nil
+	}
+	file, err := os.OpenFile(config.LogFile, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		return err
+	}
+	log.SetOutput(file)
+	return nil
+}
+
+// Logger returns a logger for the named server which writes JSON to the log output,
+// or nil if verbose logging is disabled
+func (config *Config) Logger(name string) *slog.Logger {
+	if !config.Verbose {
+		return nil
+	}
+	return slog.New(slog.NewJSONHandler(log.Writer(), nil)).With("server", name)
+}
+
+// ServeAdmin serves handler over plain HTTP on the admin listener, if one is configured.
+// The listener is opened before ServeAdmin returns, but served in a new goroutine.
+func (config *Config) ServeAdmin(handler http.Handler) error {
+	if config.AdminListen == "" {
+		return nil
+	}
+	adminListener, err := listener.Open(config.AdminListen)
+	if err != nil {
+		return fmt.Errorf("error opening admin listener: %w", err)
+	}
+	httpServer := &http.Server{
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 30 * time.Second,
+		Handler:      handler,
+	}
+	go func() { log.Fatal(httpServer.Serve(adminListener)) }()
+	return nil
+}
+
+// Validate checks the server's configuration for errors
+func (s *Server) Validate() error {
+	if s.Cert == "" {
+		return errors.New("no certificate or hostname specified")
+	}
+	if _, err := s.TLSConfig(); err != nil {
+		return err
+	}
+	if !slices.Contains(ClientAuthModes, s.ClientAuth) {
+		return fmt.Errorf("unknown client_auth mode %q (should be %s)", s.ClientAuth, strings.Join(ClientAuthModes, ", "))
+	}
+	if (s.ClientAuth == "verify" || s.ClientAuth == "verify_if_given") && s.CABundle == "" {
+		return fmt.Errorf("client_auth mode %q requires a CA bundle", s.ClientAuth)
+	}
+	for _, name := range s.SignatureAlgorithms {
+		if _, err := ParseSignatureAlgorithm(name); err != nil {
+			return err
+		}
+	}
+	for _, endpoint := range s.Endpoints {
+		if !strings.HasPrefix(endpoint, "/") {
+			return fmt.Errorf("endpoint %q does not start with /", endpoint)
+		}
+	}
+	return nil
+}
+
+// EndpointEnabled reports whether the given path should be served
+func (s *Server) EndpointEnabled(path string) bool {
+	return len(s.Endpoints) == 0 || slices.Contains(s.Endpoints, path)
+}
+
+// GetCertificate returns a function which obtains the server's certificate, either
+// from a file or automatically using ACME
+func (s *Server) GetCertificate() cert.GetCertificateFunc {
+	if strings.HasPrefix(s.Cert, "/") {
+		// assume it's a path to a certificate
+		return cert.GetCertificateFromFile(s.Cert)
+	} else {
+		// assume it's a hostname
+		return cert.GetCertificateAutomatically([]string{s.Cert})
+	}
+}
+
+var versions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+func parseVersion(str string) (uint16, error) {
+	if str == "" {
+		return 0, nil
+	}
+	version, ok := versions[strings.TrimPrefix(strings.ToLower(str), "tls")]
+	if !ok {
+		return 0, fmt.Errorf("unknown TLS version %q (should be 1.0, 1.1, 1.2, or 1.3)", str)
+	}
+	return version, nil
+}
+
+func parseCipherSuite(name string) (uint16, error) {
+	for _, suite := range slices.Concat(tls.CipherSuites(), tls.InsecureCipherSuites()) {
+		if suite.Name == name {
+			return suite.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown or unsupported cipher suite %q", name)
+}
+
+// ParseSignatureAlgorithm returns the code point of the named signature algorithm (e.g. "ecdsa_secp256r1_sha256")
+func ParseSignatureAlgorithm(name string) (uint16, error) {
+	for code, algorithmName := range tlshacks.SignatureAlgorithmNames {
+		if algorithmName == name {
+			return code, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown signature algorithm %q", name)
+}
+
+// TLSConfig returns a tls.Config with the server's TLS settings, not including the certificate
+func (s *Server) TLSConfig() (*tls.Config, error) {
+	config := &tls.Config{
+		NextProtos: slices.Clone(s.ALPN),
+	}
+	if !slices.Contains(config.NextProtos, acme.ALPNProto) {
+		config.NextProtos = append(config.NextProtos, acme.ALPNProto)
+	}
+	var err error
+	if config.MinVersion, err = parseVersion(s.MinVersion); err != nil {
+		return nil, err
+	}
+	if config.MaxVersion, err = parseVersion(s.MaxVersion); err != nil {
+		return nil, err
+	}
+	if config.MinVersion != 0 && config.MaxVersion != 0 && config.MinVersion > config.MaxVersion {
+		return nil, fmt.Errorf("min_version %s is greater than max_version %s", s.MinVersion, s.MaxVersion)
+	}
+	for _, name := range s.CipherSuites {
+		id, err := parseCipherSuite(name)
+		if err != nil {
+			return nil, err
+		}
+		config.CipherSuites = append(config.CipherSuites, id)
+	}
+	return config, nil
+}
+
+// HTTPServer returns an http.Server with the server's timeouts and keep-alive setting
+func (s *Server) HTTPServer(handler http.Handler) *http.Server {
+	httpServer := &http.Server{
+		ReadTimeout:  time.Duration(s.ReadTimeout),
+		WriteTimeout: time.Duration(s.WriteTimeout),
+		IdleTimeout:  time.Duration(s.IdleTimeout),
+		Handler:      handler,
+	}
+	httpServer.SetKeepAlivesEnabled(*s.KeepAlives)
+	return httpServer
+}
@@ -56,8 +56,7 @@ func JA3String(hello *ClientHelloInfo) string {
 			}
 			extensions += strconv.FormatUint(uint64(ext.Type), 10)
 		}
-		if ext.Type == 10 {
-			data := ext.Data.(*SupportedGroupsData)
+		if data, ok := ext.Data.(*SupportedGroupsData); ok && ext.Type == 10 {
 			for _, g := range data.Groups {
 				if (g & 0x0F0F) != 0x0A0A {
 					if len(groups) > 0 {
@@ -66,8 +65,7 @@ func JA3String(hello *ClientHelloInfo) string {
 					groups += strconv.FormatUint(uint64(g), 10)
 				}
 			}
-		} else if ext.Type == 11 {
-			data := ext.Data.(*ECPointFormatsData)
+		} else if data, ok := ext.Data.(*ECPointFormatsData); ok && ext.Type == 11 {
 			for _, f := range data.Formats {
 				if len(pointFormats) > 0 {
 					pointFormats += "-"
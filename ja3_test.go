@@ -0,0 +1,62 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package tlshacks
+
+import (
+	"testing"
+)
+
+// The expected fingerprints were computed independently of this package
+func TestJA3(t *testing.T) {
+	info := readTestHello(t, "firefox-128.hex")
+	const expectedString = "771,4865-4867-4866-49195-49199-52393-52392-49196-49200-49162-49161-49171-49172-156-157-47-53,0-23-65281-10-11-35-16-5-34-51-43-13-45-28-65037,29-23-24-25-256-257,0"
+	if s := JA3String(info); s != expectedString {
+		t.Errorf("JA3String is %q, expected %q", s, expectedString)
+	}
+	if fingerprint := JA3Fingerprint(expectedString); fingerprint != "b5001237acdf006056b409cc433726b0" {
+		t.Errorf("JA3Fingerprint is %q, expected b5001237acdf006056b409cc433726b0", fingerprint)
+	}
+}
+
+func TestJA3IgnoresGrease(t *testing.T) {
+	builder := &ClientHelloBuilder{
+		CipherSuites: []uint16{0x1301, 0xc02b},
+		Extensions: []BuilderExtension{
+			ServerNameExtension("example.com"),
+			SupportedGroupsExtension(29, 23),
+			ECPointFormatsExtension(0),
+		},
+		Grease: true,
+	}
+	info, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const expectedString = "771,4865-49195,0-10-11-43,29-23,0"
+	if s := JA3String(info); s != expectedString {
+		t.Errorf("JA3String is %q, expected %q", s, expectedString)
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright (C) 2022 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package tlshacks
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+func ja4Version(hello *ClientHelloInfo) string {
+	version := hello.Version
+	for _, ext := range hello.Extensions {
+		if data, ok := ext.Data.(*SupportedVersionsData); ok && ext.Type == 43 {
+			version = 0
+			for _, v := range data.Versions {
+				if !isGreaseValue(uint16(v)) && v > version {
+					version = v
+				}
+			}
+		}
+	}
+	switch version {
+	case 0x0304:
+		return "13"
+	case 0x0303:
+		return "12"
+	case 0x0302:
+		return "11"
+	case 0x0301:
+		return "10"
+	case 0x0300:
+		return "s3"
+	case 0x0002:
+		return "s2"
+	case 0xfeff:
+		return "d1"
+	case 0xfefd:
+		return "d2"
+	case 0xfefc:
+		return "d3"
+	default:
+		return "00"
+	}
+}
+
+func isAlphanumeric(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z')
+}
+
+func ja4ALPN(protocols []string) string {
+	if len(protocols) == 0 || len(protocols[0]) == 0 {
+		return "00"
+	}
+	first, last := protocols[0][0], protocols[0][len(protocols[0])-1]
+	if isAlphanumeric(first) && isAlphanumeric(last) {
+		return string([]byte{first, last})
+	}
+	firstHex, lastHex := hex.EncodeToString([]byte{first}), hex.EncodeToString([]byte{last})
+	return firstHex[:1] + lastHex[1:]
+}
+
+func ja4Count(n int) string {
+	return fmt.Sprintf("%02d", min(n, 99))
+}
+
+func ja4Hash(s string) string {
+	if s == "" {
+		return "000000000000"
+	}
+	digest := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(digest[:])[:12]
+}
+
+// JA4String returns the unhashed form of the JA4 fingerprint (known as ja4_r), in which
+// the cipher suites, extensions, and signature algorithms are listed in full
+func JA4String(hello *ClientHelloInfo) string {
+	var (
+		ciphers    []string
+		extensions []string
+		algorithms []string
+		numExts    int
+		sni        = "i"
+		protocols  []string
+	)
+
+	for _, cipher := range hello.CipherSuites {
+		if !isGreaseValue(cipher.CodeUint16()) {
+			ciphers = append(ciphers, fmt.Sprintf("%04x", cipher.CodeUint16()))
+		}
+	}
+
+	for _, ext := range hello.Extensions {
+		if isGreaseValue(ext.Type) {
+			continue
+		}
+		numExts++
+		switch ext.Type {
+		case 0:
+			sni = "d"
+		case 16:
+			if data, ok := ext.Data.(*ALPNData); ok {
+				protocols = data.Protocols
+			}
+		default:
+			extensions = append(extensions, fmt.Sprintf("%04x", ext.Type))
+		}
+		if data, ok := ext.Data.(*SignatureAlgorithmsData); ok && ext.Type == 13 {
+			for _, alg := range data.Algorithms {
+				if !isGreaseValue(alg) {
+					algorithms = append(algorithms, fmt.Sprintf("%04x", alg))
+				}
+			}
+		}
+	}
+
+	slices.Sort(ciphers)
+	slices.Sort(extensions)
+
+	ja4 := fmt.Sprintf("t%s%s%s%s%s_%s_%s", ja4Version(hello), sni, ja4Count(len(ciphers)), ja4Count(numExts), ja4ALPN(protocols), strings.Join(ciphers, ","), strings.Join(extensions, ","))
+	if len(algorithms) > 0 {
+		ja4 += "_" + strings.Join(algorithms, ",")
+	}
+	return ja4
+}
+
+// JA4Fingerprint converts the output of JA4String to the hashed JA4 fingerprint
+func JA4Fingerprint(ja4string string) string {
+	fields := strings.SplitN(ja4string, "_", 3)
+	if len(fields) != 3 {
+		return ""
+	}
+	return fields[0] + "_" + ja4Hash(fields[1]) + "_" + ja4Hash(fields[2])
+}
@@ -0,0 +1,89 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package tlshacks
+
+import (
+	"testing"
+)
+
+// TestJA4Fingerprint uses the example from the JA4 specification
+func TestJA4Fingerprint(t *testing.T) {
+	const ja4String = "t13d1516h2_002f,0035,009c,009d,1301,1302,1303,c013,c014,c02b,c02c,c02f,c030,cca8,cca9_0005,000a,000b,000d,0012,0015,0017,001b,0023,002b,002d,0033,4469,ff01_0403,0804,0401,0503,0805,0501,0806,0601"
+	if fingerprint := JA4Fingerprint(ja4String); fingerprint != "t13d1516h2_8daaf6152771_e5627efa2ab1" {
+		t.Errorf("JA4Fingerprint is %q, expected t13d1516h2_8daaf6152771_e5627efa2ab1", fingerprint)
+	}
+}
+
+// TestJA4 checks the fingerprints of browser ClientHellos against their published JA4 fingerprints
+func TestJA4(t *testing.T) {
+	tests := []struct {
+		file        string
+		fingerprint string
+	}{
+		{"chrome-131.hex", "t13d1516h2_8daaf6152771_02713d6af862"},
+		{"chrome-133.hex", "t13d1516h2_8daaf6152771_d8a2da3f94cd"},
+	}
+	for _, test := range tests {
+		info := readTestHello(t, test.file)
+		if fingerprint := JA4Fingerprint(JA4String(info)); fingerprint != test.fingerprint {
+			t.Errorf("%s: JA4 fingerprint is %q, expected %q", test.file, fingerprint, test.fingerprint)
+		}
+	}
+}
+
+func TestJA4WithoutExtensions(t *testing.T) {
+	info, err := (&ClientHelloBuilder{MaxVersion: 0x0303, CipherSuites: []uint16{0x1301, 0x002f}}).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const expectedString = "t12i020000_002f,1301_"
+	if s := JA4String(info); s != expectedString {
+		t.Errorf("JA4String is %q, expected %q", s, expectedString)
+	}
+	if fingerprint := JA4Fingerprint(expectedString); fingerprint != "t12i020000_84cdcbea686c_000000000000" {
+		t.Errorf("JA4Fingerprint is %q, expected t12i020000_84cdcbea686c_000000000000", fingerprint)
+	}
+}
+
+func TestJA4ALPN(t *testing.T) {
+	tests := []struct {
+		protocols []string
+		expected  string
+	}{
+		{nil, "00"},
+		{[]string{""}, "00"},
+		{[]string{"h2", "http/1.1"}, "h2"},
+		{[]string{"http/1.1"}, "h1"},
+		{[]string{"h"}, "hh"},
+		{[]string{"\xab\xcd"}, "ad"},
+		{[]string{"h\xff"}, "6f"},
+	}
+	for _, test := range tests {
+		if alpn := ja4ALPN(test.protocols); alpn != test.expected {
+			t.Errorf("ja4ALPN(%q) is %q, expected %q", test.protocols, alpn, test.expected)
+		}
+	}
+}
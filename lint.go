@@ -0,0 +1,284 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package tlshacks
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // violates a MUST or MUST NOT
+	SeverityWarning Severity = "warning" // violates a SHOULD or SHOULD NOT, or is otherwise likely to cause problems
+	SeverityInfo    Severity = "info"    // unusual but permitted
+)
+
+type Finding struct {
+	Severity  Severity `json:"severity"`
+	Code      string   `json:"code"`
+	Message   string   `json:"message"`
+	Reference string   `json:"reference"`
+}
+
+type linter struct {
+	hello    *ClientHelloInfo
+	findings []Finding
+}
+
+func (l *linter) add(severity Severity, code string, reference string, format string, args ...any) {
+	l.findings = append(l.findings, Finding{
+		Severity:  severity,
+		Code:      code,
+		Message:   fmt.Sprintf(format, args...),
+		Reference: reference,
+	})
+}
+
+// Lint checks the ClientHello for violations of the rules in the TLS RFCs
+// which span multiple fields or extensions
+func Lint(hello *ClientHelloInfo) []Finding {
+	l := &linter{hello: hello, findings: []Finding{}}
+	l.checkExtensions()
+	l.checkVersions()
+	l.checkKeyShare()
+	l.checkCompression()
+	l.checkServerName()
+	l.checkRenegotiation()
+	l.checkHeartbeat()
+	l.checkGrease()
+	return l.findings
+}
+
+func (l *linter) offeredVersions() []ProtocolVersion {
+	if ext := l.hello.Extension(43); ext != nil {
+		if data, ok := ext.Data.(*SupportedVersionsData); ok && data.Valid {
+			return data.Versions
+		}
+	}
+	return []ProtocolVersion{l.hello.Version}
+}
+
+func (l *linter) offersTLS13() bool {
+	return slices.Contains(l.offeredVersions(), 0x0304)
+}
+
+func (l *linter) offersTLS12OrBelow() bool {
+	return slices.ContainsFunc(l.offeredVersions(), func(v ProtocolVersion) bool {
+		return v <= 0x0303 && !isGreaseValue(uint16(v))
+	})
+}
+
+func (l *linter) checkExtensions() {
+	seen := make(map[uint16]bool)
+	for i, ext := range l.hello.Extensions {
+		if seen[ext.Type] {
+			l.add(SeverityError, "duplicate_extension", "RFC 8446, Section 4.2", "Extension %d (%s) appears more than once", ext.Type, ext.Name)
+		}
+		seen[ext.Type] = true
+
+		if !extensionValid(ext.Data) {
+			l.add(SeverityError, "malformed_extension", Extensions[ext.Type].Reference, "Extension %d (%s) is malformed", ext.Type, ext.Name)
+		}
+
+		if ext.Type == 41 && i != len(l.hello.Extensions)-1 {
+			l.add(SeverityError, "pre_shared_key_not_last", "RFC 8446, Section 4.2.11", "The pre_shared_key extension is not the last extension")
+		}
+	}
+}
+
+func (l *linter) checkVersions() {
+	supportedVersions := l.hello.Extension(43)
+	if l.hello.Version > 0x0303 || (supportedVersions != nil && l.hello.Version != 0x0303) {
+		l.add(SeverityError, "legacy_version_not_tls12", "RFC 8446, Section 4.1.2", "legacy_version is %s instead of TLS 1.2", l.hello.Version)
+	}
+
+	if supportedVersions == nil {
+		hasTLS13Suite := slices.ContainsFunc(l.hello.CipherSuites, func(suite CipherSuite) bool {
+			return suite.CodeUint16() >= 0x1301 && suite.CodeUint16() <= 0x13FF
+		})
+		if hasTLS13Suite || l.hello.Extension(51) != nil {
+			l.add(SeverityWarning, "tls13_without_supported_versions", "RFC 8446, Section 4.2.1", "TLS 1.3 cipher suites or key_share offered without supported_versions, so TLS 1.3 cannot be negotiated")
+		}
+		return
+	}
+
+	if !l.offersTLS13() {
+		return
+	}
+	if l.hello.Extension(51) == nil && l.hello.Extension(41) == nil {
+		l.add(SeverityError, "tls13_without_key_share", "RFC 8446, Section 9.2", "TLS 1.3 offered without key_share or pre_shared_key")
+	}
+	if l.hello.Extension(13) == nil && l.hello.Extension(41) == nil {
+		l.add(SeverityError, "tls13_without_signature_algorithms", "RFC 8446, Section 9.2", "TLS 1.3 offered without signature_algorithms")
+	}
+	if (l.hello.Extension(10) == nil) != (l.hello.Extension(51) == nil) {
+		l.add(SeverityError, "supported_groups_key_share_mismatch", "RFC 8446, Section 9.2", "supported_groups and key_share must either both be present or both be absent")
+	}
+	if l.hello.Extension(41) != nil && l.hello.Extension(45) == nil {
+		l.add(SeverityError, "pre_shared_key_without_modes", "RFC 8446, Section 4.2.9", "pre_shared_key offered without psk_key_exchange_modes")
+	}
+}
+
+func (l *linter) checkKeyShare() {
+	keyShare := l.hello.Extension(51)
+	if keyShare == nil {
+		return
+	}
+	shares, ok := keyShare.Data.(*KeyShareData)
+	if !ok || !shares.Valid {
+		return
+	}
+	var groups []uint16
+	if ext := l.hello.Extension(10); ext != nil {
+		if data, ok := ext.Data.(*SupportedGroupsData); ok {
+			groups = data.Groups
+		}
+	}
+
+	seen := make(map[uint16]bool)
+	lastIndex := -1
+	for _, share := range shares.Shares {
+		if seen[share.Group] {
+			l.add(SeverityError, "duplicate_key_share", "RFC 8446, Section 4.2.8", "key_share contains more than one share for group %d", share.Group)
+			continue
+		}
+		seen[share.Group] = true
+		index := slices.Index(groups, share.Group)
+		if index == -1 {
+			l.add(SeverityError, "key_share_group_not_supported", "RFC 8446, Section 4.2.8", "key_share contains group %d, which is not in supported_groups", share.Group)
+			continue
+		}
+		if index < lastIndex {
+			l.add(SeverityError, "key_share_order", "RFC 8446, Section 4.2.8", "key_share groups are not in the same order as supported_groups")
+		}
+		lastIndex = index
+	}
+}
+
+func (l *linter) checkCompression() {
+	methods := l.hello.CompressionMethods
+	if !slices.Contains(methods, 0) {
+		l.add(SeverityError, "no_null_compression", "RFC 5246, Section 7.4.1.2", "compression_methods does not contain the null compression method")
+	}
+	if l.offersTLS13() && (len(methods) != 1 || methods[0] != 0) {
+		l.add(SeverityError, "tls13_compression", "RFC 8446, Section 4.1.2", "TLS 1.3 offered but compression_methods is not exactly the null compression method")
+	}
+	for _, method := range methods {
+		if method != 0 {
+			l.add(SeverityWarning, "compression_offered", "RFC 7525, Section 3.3", "Compression method %d offered; TLS compression is vulnerable to attacks such as CRIME", method)
+		}
+	}
+}
+
+func (l *linter) checkServerName() {
+	ext := l.hello.Extension(0)
+	if ext == nil {
+		return
+	}
+	data, ok := ext.Data.(*ServerNameData)
+	if !ok || !data.Valid {
+		return
+	}
+	hostname := data.HostName
+	if _, err := netip.ParseAddr(strings.Trim(hostname, "[]")); err == nil {
+		l.add(SeverityError, "server_name_ip_literal", "RFC 6066, Section 3", "server_name contains an IP address literal (%s)", hostname)
+	}
+	if strings.HasSuffix(hostname, ".") {
+		l.add(SeverityError, "server_name_trailing_dot", "RFC 6066, Section 3", "server_name contains a trailing dot (%s)", hostname)
+	}
+}
+
+func (l *linter) checkRenegotiation() {
+	hasSCSV := slices.ContainsFunc(l.hello.CipherSuites, func(suite CipherSuite) bool {
+		return suite.CodeUint16() == 0x00FF
+	})
+	ext := l.hello.Extension(0xFF01)
+
+	if ext != nil {
+		data, ok := ext.Data.(*RenegotiationInfoData)
+		if ok && data.Valid && len(data.RenegotiatedConnection) != 0 {
+			l.add(SeverityError, "renegotiation_info_not_empty", "RFC 5746, Section 3.4", "renegotiation_info in an initial ClientHello is not empty")
+		}
+	}
+	if !l.offersTLS12OrBelow() {
+		return
+	}
+	if ext == nil && !hasSCSV {
+		l.add(SeverityWarning, "no_renegotiation_indication", "RFC 5746, Section 3.4", "Neither renegotiation_info nor TLS_EMPTY_RENEGOTIATION_INFO_SCSV is present")
+	} else if ext != nil && hasSCSV {
+		l.add(SeverityInfo, "renegotiation_info_and_scsv", "RFC 5746, Section 3.4", "Both renegotiation_info and TLS_EMPTY_RENEGOTIATION_INFO_SCSV are present")
+	}
+}
+
+// checkHeartbeat flags the heartbeat extension, which few clients need and whose
+// implementations have a history of vulnerabilities
+func (l *linter) checkHeartbeat() {
+	if ext := l.hello.Extension(15); ext != nil {
+		if data, ok := ext.Data.(*HeartbeatData); ok && data.Mode == 1 {
+			l.add(SeverityWarning, "heartbeat_offered", "RFC 6520, Section 2", "The heartbeat extension allows the server to send heartbeat requests; heartbeat implementations have been vulnerable to attacks such as Heartbleed")
+		} else {
+			l.add(SeverityInfo, "heartbeat_offered", "RFC 6520, Section 2", "The heartbeat extension is offered")
+		}
+	}
+}
+
+// checkGrease looks for values that follow the GREASE pattern but are not
+// one of the reserved values, which is likely an implementation bug
+func (l *linter) checkGrease() {
+	check := func(field string, value uint16) {
+		if (value&0x0F0F) == 0x0A0A && !isGreaseValue(value) {
+			l.add(SeverityWarning, "malformed_grease", "RFC 8701, Section 2", "%s contains 0x%04x, which resembles but is not a GREASE value", field, value)
+		}
+	}
+	for _, suite := range l.hello.CipherSuites {
+		check("cipher_suites", suite.CodeUint16())
+	}
+	for _, ext := range l.hello.Extensions {
+		check("extensions", ext.Type)
+		switch data := ext.Data.(type) {
+		case *SupportedGroupsData:
+			for _, group := range data.Groups {
+				check("supported_groups", group)
+			}
+		case *SignatureAlgorithmsData:
+			for _, alg := range data.Algorithms {
+				check(ext.Name, alg)
+			}
+		case *SupportedVersionsData:
+			for _, version := range data.Versions {
+				check("supported_versions", uint16(version))
+			}
+		case *KeyShareData:
+			for _, share := range data.Shares {
+				check("key_share", share.Group)
+			}
+		}
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package tlshacks
+
+import (
+	"slices"
+	"testing"
+)
+
+// lintBuilder returns a builder for a TLS 1.2 and 1.3 ClientHello with no lint findings
+func lintBuilder() *ClientHelloBuilder {
+	return &ClientHelloBuilder{
+		CipherSuites: []uint16{0x1301, 0x1302, 0xc02b},
+		Extensions: []BuilderExtension{
+			ServerNameExtension("example.com"),
+			SupportedGroupsExtension(29, 23),
+			ECPointFormatsExtension(0),
+			SignatureAlgorithmsExtension(0x0403, 0x0804),
+			RenegotiationInfoExtension(),
+			PSKKeyExchangeModesExtension(1),
+			KeyShareExtension(KeyShareEntry{Group: 29}),
+		},
+	}
+}
+
+func (builder *ClientHelloBuilder) withoutExtensions(types ...uint16) *ClientHelloBuilder {
+	builder.Extensions = slices.DeleteFunc(builder.Extensions, func(ext BuilderExtension) bool { return slices.Contains(types, ext.Type) })
+	return builder
+}
+
+func (builder *ClientHelloBuilder) replaceExtension(ext BuilderExtension) *ClientHelloBuilder {
+	for i := range builder.Extensions {
+		if builder.Extensions[i].Type == ext.Type {
+			builder.Extensions[i] = ext
+		}
+	}
+	return builder
+}
+
+// testPSKExtension returns a well-formed pre_shared_key extension with one identity
+func testPSKExtension() BuilderExtension {
+	identity := concat(lengthPrefixed(2, []byte("ticket")), []byte{0, 0, 0, 0})
+	binder := lengthPrefixed(1, make([]byte, 32))
+	return BuilderExtension{Type: 41, Data: concat(lengthPrefixed(2, identity), lengthPrefixed(2, binder))}
+}
+
+func findingCodes(findings []Finding) []string {
+	codes := []string{}
+	for _, finding := range findings {
+		codes = append(codes, finding.Code)
+	}
+	return codes
+}
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *ClientHelloBuilder
+		codes   []string
+	}{
+		{"clean", lintBuilder(), nil},
+		{"clean with GREASE", func() *ClientHelloBuilder { b := lintBuilder(); b.Grease = true; return b }(), nil},
+
+		{"duplicate extension", func() *ClientHelloBuilder {
+			b := lintBuilder()
+			b.Extensions = append(b.Extensions, ServerNameExtension("example.com"))
+			return b
+		}(), []string{"duplicate_extension"}},
+
+		{"pre_shared_key last", func() *ClientHelloBuilder {
+			b := lintBuilder()
+			b.Extensions = append(b.Extensions, SupportedVersionsExtension(0x0304, 0x0303), testPSKExtension())
+			return b
+		}(), nil},
+		{"pre_shared_key not last", func() *ClientHelloBuilder {
+			b := lintBuilder()
+			b.Extensions = append(b.Extensions, testPSKExtension(), SupportedVersionsExtension(0x0304, 0x0303))
+			return b
+		}(), []string{"pre_shared_key_not_last"}},
+		{"pre_shared_key without modes", func() *ClientHelloBuilder {
+			b := lintBuilder().withoutExtensions(45)
+			b.Extensions = append(b.Extensions, SupportedVersionsExtension(0x0304, 0x0303), testPSKExtension())
+			return b
+		}(), []string{"pre_shared_key_without_modes"}},
+
+		{"TLS 1.3 without key_share", lintBuilder().withoutExtensions(10, 51), []string{"tls13_without_key_share"}},
+		{"TLS 1.3 without key_share but with pre_shared_key", func() *ClientHelloBuilder {
+			b := lintBuilder().withoutExtensions(10, 51)
+			b.Extensions = append(b.Extensions, SupportedVersionsExtension(0x0304, 0x0303), testPSKExtension())
+			return b
+		}(), nil},
+		{"TLS 1.3 with supported_groups but without key_share", lintBuilder().withoutExtensions(51), []string{"tls13_without_key_share", "supported_groups_key_share_mismatch"}},
+		{"TLS 1.3 without signature_algorithms", lintBuilder().withoutExtensions(13), []string{"tls13_without_signature_algorithms"}},
+		{"TLS 1.3 without supported_versions", func() *ClientHelloBuilder { b := lintBuilder(); b.MaxVersion = 0x0303; return b }(), []string{"tls13_without_supported_versions"}},
+		{"TLS 1.2 without TLS 1.3 suites or key_share", func() *ClientHelloBuilder {
+			b := lintBuilder().withoutExtensions(45, 51)
+			b.MaxVersion = 0x0303
+			b.CipherSuites = []uint16{0xc02b}
+			return b
+		}(), nil},
+
+		{"key_share group not in supported_groups", lintBuilder().replaceExtension(SupportedGroupsExtension(23)), []string{"key_share_group_not_supported"}},
+		{"key_share out of order", lintBuilder().replaceExtension(KeyShareExtension(KeyShareEntry{Group: 23}, KeyShareEntry{Group: 29})), []string{"key_share_order"}},
+		{"duplicate key_share", lintBuilder().replaceExtension(KeyShareExtension(KeyShareEntry{Group: 29}, KeyShareEntry{Group: 29})), []string{"duplicate_key_share"}},
+
+		{"compression in TLS 1.2", func() *ClientHelloBuilder {
+			b := lintBuilder().withoutExtensions(45, 51)
+			b.MaxVersion = 0x0303
+			b.CipherSuites = []uint16{0xc02b}
+			b.CompressionMethods = []uint8{1, 0}
+			return b
+		}(), []string{"compression_offered"}},
+		{"compression in TLS 1.3", func() *ClientHelloBuilder { b := lintBuilder(); b.CompressionMethods = []uint8{1, 0}; return b }(), []string{"tls13_compression", "compression_offered"}},
+		{"no null compression", func() *ClientHelloBuilder {
+			b := lintBuilder().withoutExtensions(45, 51)
+			b.MaxVersion = 0x0303
+			b.CipherSuites = []uint16{0xc02b}
+			b.CompressionMethods = []uint8{1}
+			return b
+		}(), []string{"no_null_compression", "compression_offered"}},
+
+		{"server_name IPv4 literal", lintBuilder().replaceExtension(ServerNameExtension("192.0.2.1")), []string{"server_name_ip_literal"}},
+		{"server_name IPv6 literal", lintBuilder().replaceExtension(ServerNameExtension("[2001:db8::1]")), []string{"server_name_ip_literal"}},
+		{"server_name trailing dot", lintBuilder().replaceExtension(ServerNameExtension("example.com.")), []string{"server_name_trailing_dot"}},
+		{"server_name with digits", lintBuilder().replaceExtension(ServerNameExtension("192.0.2.1.example")), nil},
+
+		{"renegotiation_info and SCSV", func() *ClientHelloBuilder {
+			b := lintBuilder()
+			b.CipherSuites = append(b.CipherSuites, 0x00ff)
+			return b
+		}(), []string{"renegotiation_info_and_scsv"}},
+		{"SCSV only", func() *ClientHelloBuilder {
+			b := lintBuilder().withoutExtensions(0xff01)
+			b.CipherSuites = append(b.CipherSuites, 0x00ff)
+			return b
+		}(), nil},
+		{"no renegotiation indication", lintBuilder().withoutExtensions(0xff01), []string{"no_renegotiation_indication"}},
+		{"no renegotiation indication in TLS 1.3 only", func() *ClientHelloBuilder {
+			b := lintBuilder().withoutExtensions(0xff01)
+			b.MinVersion = 0x0304
+			return b
+		}(), nil},
+		{"renegotiation_info not empty", lintBuilder().replaceExtension(BuilderExtension{Type: 0xff01, Data: []byte{1, 0}}), []string{"renegotiation_info_not_empty"}},
+
+		{"malformed GREASE cipher suite", func() *ClientHelloBuilder {
+			b := lintBuilder()
+			b.CipherSuites = append([]uint16{0x1a2a}, b.CipherSuites...)
+			return b
+		}(), []string{"malformed_grease"}},
+		{"malformed GREASE group", lintBuilder().replaceExtension(SupportedGroupsExtension(29, 23, 0x0a1a)), []string{"malformed_grease"}},
+	}
+	for _, test := range tests {
+		info, err := test.builder.Build()
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+		if codes := findingCodes(Lint(info)); !slices.Equal(codes, test.codes) {
+			t.Errorf("%s: findings are %v, expected %v", test.name, codes, test.codes)
+		}
+	}
+}
+
+// TestLintLegacyVersion checks that a legacy_version other than TLS 1.2 alongside
+// supported_versions is reported exactly once
+func TestLintLegacyVersion(t *testing.T) {
+	for _, version := range []ProtocolVersion{0x0304, 0x0302} {
+		message, err := lintBuilder().Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		message[4], message[5] = version.Hi(), version.Lo() // after the handshake type and length
+		info := UnmarshalClientHello(message)
+		if info == nil {
+			t.Fatal("modified ClientHello does not parse")
+		}
+		findings := Lint(info)
+		if len(findings) != 1 || findings[0].Code != "legacy_version_not_tls12" || findings[0].Severity != SeverityError {
+			t.Errorf("legacy_version %s: findings are %+v, expected one legacy_version_not_tls12 error", version, findings)
+		}
+	}
+}
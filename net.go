@@ -37,12 +37,32 @@ type Conn struct {
 	net.Conn
 	ClientHello []byte
 
-	reader io.Reader
+	reader  io.Reader
+	capture *handshakeCapture
 }
 
 func (conn *Conn) Read(p []byte) (int, error) { return conn.reader.Read(p) }
 
+// ClientFlight returns what the client has sent after the ClientHello so far, or nil
+// if the Conn was not created with NewCapturingConn
+func (conn *Conn) ClientFlight() *ClientFlight {
+	if conn.capture == nil {
+		return nil
+	}
+	return conn.capture.clientFlight()
+}
+
 func NewConn(conn net.Conn) (*Conn, error) {
+	return newConn(conn, false)
+}
+
+// NewCapturingConn is like NewConn, but the returned Conn also captures the plaintext
+// handshake messages which the client sends after the ClientHello, available from ClientFlight
+func NewCapturingConn(conn net.Conn) (*Conn, error) {
+	return newConn(conn, true)
+}
+
+func newConn(conn net.Conn, capture bool) (*Conn, error) {
 	peekedBytes := new(bytes.Buffer)
 	if err := conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
 		return nil, err
@@ -54,27 +74,97 @@ func NewConn(conn net.Conn) (*Conn, error) {
 	if err := conn.SetReadDeadline(time.Time{}); err != nil {
 		return nil, err
 	}
-	return &Conn{
+	newConn := &Conn{
 		Conn:        conn,
 		ClientHello: clientHello,
 		reader:      io.MultiReader(peekedBytes, conn),
-	}, nil
+	}
+	if capture {
+		newConn.capture = new(handshakeCapture)
+		newConn.reader = io.MultiReader(peekedBytes, io.TeeReader(conn, newConn.capture))
+	}
+	return newConn, nil
+}
+
+// ListenerHooks are called by a listener created with NewListenerWithHooks,
+// for observing the listener.  Hooks may be nil, and are called concurrently.
+// (CaptureClientFlight isn't a hook, but it's set up alongside them.)
+type ListenerHooks struct {
+	// Accepted is called when a connection is accepted, before its ClientHello is read
+	Accepted func(conn net.Conn)
+
+	// ClientHello is called after the connection's ClientHello has been read,
+	// with the time it took to read
+	ClientHello func(conn *Conn, elapsed time.Duration)
+
+	// Error is called when the ClientHello can't be read, in which case
+	// the connection is closed
+	Error func(conn net.Conn, err error, elapsed time.Duration)
+
+	// CaptureClientFlight causes the listener's Conns to be created with NewCapturingConn
+	CaptureClientFlight bool
+}
+
+// CombineListenerHooks returns ListenerHooks which call the hooks of each of the
+// given ListenerHooks in turn.  CaptureClientFlight is set if any of them set it.
+func CombineListenerHooks(hooks ...*ListenerHooks) *ListenerHooks {
+	combined := new(ListenerHooks)
+	for _, h := range hooks {
+		if h.Accepted != nil {
+			prev, next := combined.Accepted, h.Accepted
+			combined.Accepted = func(conn net.Conn) {
+				if prev != nil {
+					prev(conn)
+				}
+				next(conn)
+			}
+		}
+		if h.ClientHello != nil {
+			prev, next := combined.ClientHello, h.ClientHello
+			combined.ClientHello = func(conn *Conn, elapsed time.Duration) {
+				if prev != nil {
+					prev(conn, elapsed)
+				}
+				next(conn, elapsed)
+			}
+		}
+		if h.Error != nil {
+			prev, next := combined.Error, h.Error
+			combined.Error = func(conn net.Conn, err error, elapsed time.Duration) {
+				if prev != nil {
+					prev(conn, err, elapsed)
+				}
+				next(conn, err, elapsed)
+			}
+		}
+		combined.CaptureClientFlight = combined.CaptureClientFlight || h.CaptureClientFlight
+	}
+	return combined
 }
 
 type listener struct {
 	inner  net.Listener
+	hooks  ListenerHooks
 	conns  chan net.Conn
 	errors chan error
 	done   chan struct{}
 }
 
 func NewListener(inner net.Listener) net.Listener {
+	return NewListenerWithHooks(inner, nil)
+}
+
+// NewListenerWithHooks is like NewListener, but calls hooks (if non-nil) as connections are accepted
+func NewListenerWithHooks(inner net.Listener, hooks *ListenerHooks) net.Listener {
 	listener := &listener{
 		inner:  inner,
 		conns:  make(chan net.Conn),
 		errors: make(chan error),
 		done:   make(chan struct{}),
 	}
+	if hooks != nil {
+		listener.hooks = *hooks
+	}
 	go listener.handleAccepts()
 	return listener
 }
@@ -114,12 +204,22 @@ func (listener *listener) handleAccepts() {
 }
 
 func (listener *listener) handleConnection(innerConn net.Conn) {
-	conn, err := NewConn(innerConn)
+	if listener.hooks.Accepted != nil {
+		listener.hooks.Accepted(innerConn)
+	}
+	start := time.Now()
+	conn, err := newConn(innerConn, listener.hooks.CaptureClientFlight)
 	if err != nil {
+		if listener.hooks.Error != nil {
+			listener.hooks.Error(innerConn, err, time.Since(start))
+		}
 		innerConn.Close()
 		listener.sendError(&acceptError{error: err, temporary: true})
 		return
 	}
+	if listener.hooks.ClientHello != nil {
+		listener.hooks.ClientHello(conn, time.Since(start))
+	}
 	if !listener.sendConn(conn) {
 		conn.Close()
 	}
@@ -0,0 +1,116 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package tlshacks
+
+import (
+	"fmt"
+	"maps"
+	"sync"
+)
+
+// ExtensionParser parses the data of an extension.  It must return a non-nil
+// ExtensionData, even if the data is malformed.  By convention, ExtensionData is a
+// pointer to a struct containing Raw and Valid fields.
+type ExtensionParser func([]byte) ExtensionData
+
+// Parser unmarshals ClientHellos using its own registry of extension parsers.
+// It is safe for concurrent use, including while parsers are being registered.
+// The zero value is a Parser with no extension parsers, not even the built-in
+// ones; use NewParser to start with those.
+type Parser struct {
+	mu      sync.RWMutex
+	parsers map[uint16]ExtensionParser
+}
+
+// DefaultParser is used by UnmarshalClientHello and the package-level
+// RegisterExtensionParser and OverrideExtensionParser functions
+var DefaultParser = &Parser{parsers: maps.Clone(extensionParsers)}
+
+// NewParser returns a Parser whose registry is a copy of DefaultParser's, including
+// any parsers registered with DefaultParser so far
+func NewParser() *Parser {
+	return DefaultParser.Clone()
+}
+
+// Clone returns a Parser with a copy of p's registry
+func (p *Parser) Clone() *Parser {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return &Parser{parsers: maps.Clone(p.parsers)}
+}
+
+// RegisterExtensionParser registers a parser for the given extension type.  It returns
+// an error if the type already has a parser; use OverrideExtensionParser to replace it.
+func (p *Parser) RegisterExtensionParser(extType uint16, parse ExtensionParser) error {
+	if parse == nil {
+		return fmt.Errorf("parser for extension %d is nil", extType)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, exists := p.parsers[extType]; exists {
+		return fmt.Errorf("a parser for extension %d (%s) is already registered", extType, ExtensionName(extType))
+	}
+	if p.parsers == nil {
+		p.parsers = make(map[uint16]ExtensionParser)
+	}
+	p.parsers[extType] = parse
+	return nil
+}
+
+// OverrideExtensionParser registers a parser for the given extension type, replacing any
+// existing parser, including a built-in one.  If parse is nil, the existing parser is
+// removed and extensions of the type are parsed as UnknownExtensionData.
+func (p *Parser) OverrideExtensionParser(extType uint16, parse ExtensionParser) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if parse == nil {
+		delete(p.parsers, extType)
+		return
+	}
+	if p.parsers == nil {
+		p.parsers = make(map[uint16]ExtensionParser)
+	}
+	p.parsers[extType] = parse
+}
+
+func (p *Parser) extensionParser(extType uint16) ExtensionParser {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if parse := p.parsers[extType]; parse != nil {
+		return parse
+	}
+	return ParseUnknownExtensionData
+}
+
+// RegisterExtensionParser registers a parser with DefaultParser; see (*Parser).RegisterExtensionParser
+func RegisterExtensionParser(extType uint16, parse ExtensionParser) error {
+	return DefaultParser.RegisterExtensionParser(extType, parse)
+}
+
+// OverrideExtensionParser registers a parser with DefaultParser; see (*Parser).OverrideExtensionParser
+func OverrideExtensionParser(extType uint16, parse ExtensionParser) {
+	DefaultParser.OverrideExtensionParser(extType, parse)
+}
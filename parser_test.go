@@ -0,0 +1,181 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package tlshacks
+
+import (
+	"sync"
+	"testing"
+)
+
+const testExtensionType = 0xfaf0 // unassigned
+
+type testExtensionData struct {
+	Raw []byte `json:"raw"`
+}
+
+func parseTestExtensionData(data []byte) ExtensionData {
+	return &testExtensionData{Raw: data}
+}
+
+func parserTestHello(t *testing.T) []byte {
+	t.Helper()
+	builder := lintBuilder()
+	builder.Extensions = append(builder.Extensions, BuilderExtension{Type: testExtensionType, Data: []byte("test")})
+	info, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info.Raw
+}
+
+// parsedExtensionData parses hello with p and returns the data of the given extension
+func parsedExtensionData(t *testing.T, p *Parser, hello []byte, extType uint16) ExtensionData {
+	t.Helper()
+	info := p.UnmarshalClientHello(hello)
+	if info == nil {
+		t.Fatal("UnmarshalClientHello failed")
+	}
+	ext := info.Extension(extType)
+	if ext == nil {
+		t.Fatalf("extension %d missing", extType)
+	}
+	return ext.Data
+}
+
+func TestParserRegister(t *testing.T) {
+	hello := parserTestHello(t)
+	p := NewParser()
+	if _, ok := parsedExtensionData(t, p, hello, testExtensionType).(*UnknownExtensionData); !ok {
+		t.Error("unregistered extension is not parsed as UnknownExtensionData")
+	}
+	if err := p.RegisterExtensionParser(testExtensionType, nil); err == nil {
+		t.Error("registering a nil parser succeeded")
+	}
+	if err := p.RegisterExtensionParser(testExtensionType, parseTestExtensionData); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := parsedExtensionData(t, p, hello, testExtensionType).(*testExtensionData); !ok {
+		t.Error("registered parser was not used")
+	}
+	if err := p.RegisterExtensionParser(testExtensionType, ParseUnknownExtensionData); err == nil {
+		t.Error("registering a duplicate parser succeeded")
+	}
+	if err := p.RegisterExtensionParser(0, parseTestExtensionData); err == nil {
+		t.Error("registering a parser for a built-in extension succeeded")
+	}
+	if _, ok := parsedExtensionData(t, p, hello, 0).(*ServerNameData); !ok {
+		t.Error("failed registration replaced the built-in server_name parser")
+	}
+}
+
+func TestParserOverride(t *testing.T) {
+	hello := parserTestHello(t)
+	p := NewParser()
+	p.OverrideExtensionParser(0, parseTestExtensionData)
+	if _, ok := parsedExtensionData(t, p, hello, 0).(*testExtensionData); !ok {
+		t.Error("override did not replace the built-in server_name parser")
+	}
+	p.OverrideExtensionParser(0, nil)
+	if _, ok := parsedExtensionData(t, p, hello, 0).(*UnknownExtensionData); !ok {
+		t.Error("overriding with nil did not remove the server_name parser")
+	}
+	// Once removed, the type can be registered again
+	if err := p.RegisterExtensionParser(0, parseTestExtensionData); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestParserClone(t *testing.T) {
+	hello := parserTestHello(t)
+	p := NewParser()
+	clone := p.Clone()
+	if err := p.RegisterExtensionParser(testExtensionType, parseTestExtensionData); err != nil {
+		t.Fatal(err)
+	}
+	p.OverrideExtensionParser(0, nil)
+
+	if _, ok := parsedExtensionData(t, clone, hello, testExtensionType).(*UnknownExtensionData); !ok {
+		t.Error("clone sees a parser registered on the original after cloning")
+	}
+	if _, ok := parsedExtensionData(t, clone, hello, 0).(*ServerNameData); !ok {
+		t.Error("clone sees a parser removed from the original after cloning")
+	}
+	if _, ok := parsedExtensionData(t, DefaultParser, hello, testExtensionType).(*UnknownExtensionData); !ok {
+		t.Error("DefaultParser sees a parser registered on a NewParser")
+	}
+	if _, ok := parsedExtensionData(t, DefaultParser, hello, 0).(*ServerNameData); !ok {
+		t.Error("DefaultParser sees a parser removed from a NewParser")
+	}
+}
+
+func TestParserZeroValue(t *testing.T) {
+	hello := parserTestHello(t)
+	var p Parser
+	if _, ok := parsedExtensionData(t, &p, hello, 0).(*UnknownExtensionData); !ok {
+		t.Error("zero Parser has a server_name parser")
+	}
+	if err := p.RegisterExtensionParser(testExtensionType, parseTestExtensionData); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := parsedExtensionData(t, &p, hello, testExtensionType).(*testExtensionData); !ok {
+		t.Error("parser registered on zero Parser was not used")
+	}
+
+	var q Parser
+	q.OverrideExtensionParser(0, nil)
+	q.OverrideExtensionParser(0, parseTestExtensionData)
+	if _, ok := parsedExtensionData(t, &q, hello, 0).(*testExtensionData); !ok {
+		t.Error("parser overridden on zero Parser was not used")
+	}
+}
+
+// TestParserConcurrent registers and overrides parsers while parsing; run with -race
+func TestParserConcurrent(t *testing.T) {
+	hello := parserTestHello(t)
+	p := NewParser()
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := p.RegisterExtensionParser(uint16(0xfb00+i), parseTestExtensionData); err != nil {
+				t.Error(err)
+			}
+			p.OverrideExtensionParser(testExtensionType, parseTestExtensionData)
+			_ = p.Clone()
+		}()
+		go func() {
+			defer wg.Done()
+			if p.UnmarshalClientHello(hello) == nil {
+				t.Error("UnmarshalClientHello failed")
+			}
+		}()
+	}
+	wg.Wait()
+	if _, ok := parsedExtensionData(t, p, hello, testExtensionType).(*testExtensionData); !ok {
+		t.Error("concurrently overridden parser was not used")
+	}
+}
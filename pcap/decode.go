@@ -0,0 +1,202 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package pcap
+
+import (
+	"encoding/binary"
+	"net/netip"
+)
+
+const (
+	etherTypeIPv4 = 0x0800
+	etherTypeIPv6 = 0x86DD
+	etherTypeVLAN = 0x8100
+	etherTypeQinQ = 0x88A8
+	ipProtocolTCP = 6
+	tcpFlagFIN    = 0x01
+	tcpFlagSYN    = 0x02
+	tcpFlagRST    = 0x04
+)
+
+type tcpSegment struct {
+	flow  Flow
+	seq   uint32
+	flags uint8
+	data  []byte
+}
+
+// decodeLinkLayer returns the network layer payload of the packet, along with its EtherType
+func decodeLinkLayer(linkType uint32, data []byte) (uint16, []byte, bool) {
+	switch linkType {
+	case LinkTypeEthernet:
+		if len(data) < 14 {
+			return 0, nil, false
+		}
+		etherType := binary.BigEndian.Uint16(data[12:14])
+		data = data[14:]
+		for etherType == etherTypeVLAN || etherType == etherTypeQinQ {
+			if len(data) < 4 {
+				return 0, nil, false
+			}
+			etherType = binary.BigEndian.Uint16(data[2:4])
+			data = data[4:]
+		}
+		return etherType, data, true
+	case LinkTypeLinuxSLL:
+		if len(data) < 16 {
+			return 0, nil, false
+		}
+		return binary.BigEndian.Uint16(data[14:16]), data[16:], true
+	case LinkTypeLinuxSLL2:
+		if len(data) < 20 {
+			return 0, nil, false
+		}
+		return binary.BigEndian.Uint16(data[0:2]), data[20:], true
+	case LinkTypeNull:
+		if len(data) < 4 {
+			return 0, nil, false
+		}
+		// The address family is in the host byte order of the capturing machine
+		family := binary.LittleEndian.Uint32(data[0:4])
+		if family > 0xFFFF {
+			family = binary.BigEndian.Uint32(data[0:4])
+		}
+		switch family {
+		case 2:
+			return etherTypeIPv4, data[4:], true
+		case 10, 24, 28, 30:
+			// AF_INET6 differs between operating systems
+			return etherTypeIPv6, data[4:], true
+		}
+		return 0, nil, false
+	case LinkTypeRaw:
+		if len(data) < 1 {
+			return 0, nil, false
+		}
+		switch data[0] >> 4 {
+		case 4:
+			return etherTypeIPv4, data, true
+		case 6:
+			return etherTypeIPv6, data, true
+		}
+		return 0, nil, false
+	case LinkTypeIPv4:
+		return etherTypeIPv4, data, true
+	case LinkTypeIPv6:
+		return etherTypeIPv6, data, true
+	default:
+		return 0, nil, false
+	}
+}
+
+// decodeIP returns the source and destination address and TCP payload of an IP packet.
+// Fragmented packets and non-TCP packets are rejected.
+func decodeIP(etherType uint16, data []byte) (netip.Addr, netip.Addr, []byte, bool) {
+	switch etherType {
+	case etherTypeIPv4:
+		if len(data) < 20 || data[0]>>4 != 4 {
+			return netip.Addr{}, netip.Addr{}, nil, false
+		}
+		headerLength := int(data[0]&0x0F) * 4
+		totalLength := int(binary.BigEndian.Uint16(data[2:4]))
+		fragment := binary.BigEndian.Uint16(data[6:8])
+		if headerLength < 20 || totalLength < headerLength || totalLength > len(data) {
+			return netip.Addr{}, netip.Addr{}, nil, false
+		}
+		if fragment&0x3FFF != 0 || data[9] != ipProtocolTCP {
+			// more fragments flag set or non-zero fragment offset
+			return netip.Addr{}, netip.Addr{}, nil, false
+		}
+		src := netip.AddrFrom4([4]byte(data[12:16]))
+		dst := netip.AddrFrom4([4]byte(data[16:20]))
+		return src, dst, data[headerLength:totalLength], true
+	case etherTypeIPv6:
+		if len(data) < 40 || data[0]>>4 != 6 {
+			return netip.Addr{}, netip.Addr{}, nil, false
+		}
+		payloadLength := int(binary.BigEndian.Uint16(data[4:6]))
+		nextHeader := data[6]
+		src := netip.AddrFrom16([16]byte(data[8:24]))
+		dst := netip.AddrFrom16([16]byte(data[24:40]))
+		if 40+payloadLength > len(data) {
+			return netip.Addr{}, netip.Addr{}, nil, false
+		}
+		payload := data[40 : 40+payloadLength]
+		for {
+			switch nextHeader {
+			case ipProtocolTCP:
+				return src, dst, payload, true
+			case 0, 43, 60:
+				// Hop-by-Hop Options, Routing, Destination Options
+				if len(payload) < 8 {
+					return netip.Addr{}, netip.Addr{}, nil, false
+				}
+				length := (int(payload[1]) + 1) * 8
+				if length > len(payload) {
+					return netip.Addr{}, netip.Addr{}, nil, false
+				}
+				nextHeader = payload[0]
+				payload = payload[length:]
+			default:
+				// Fragment headers and other protocols
+				return netip.Addr{}, netip.Addr{}, nil, false
+			}
+		}
+	default:
+		return netip.Addr{}, netip.Addr{}, nil, false
+	}
+}
+
+func decodeTCP(src, dst netip.Addr, data []byte) (*tcpSegment, bool) {
+	if len(data) < 20 {
+		return nil, false
+	}
+	dataOffset := int(data[12]>>4) * 4
+	if dataOffset < 20 || dataOffset > len(data) {
+		return nil, false
+	}
+	return &tcpSegment{
+		flow: Flow{
+			Source:      netip.AddrPortFrom(src.Unmap(), binary.BigEndian.Uint16(data[0:2])),
+			Destination: netip.AddrPortFrom(dst.Unmap(), binary.BigEndian.Uint16(data[2:4])),
+		},
+		seq:   binary.BigEndian.Uint32(data[4:8]),
+		flags: data[13],
+		data:  data[dataOffset:],
+	}, true
+}
+
+func decodePacket(packet *Packet) (*tcpSegment, bool) {
+	etherType, network, ok := decodeLinkLayer(packet.LinkType, packet.Data)
+	if !ok {
+		return nil, false
+	}
+	src, dst, transport, ok := decodeIP(etherType, network)
+	if !ok {
+		return nil, false
+	}
+	return decodeTCP(src, dst, transport)
+}
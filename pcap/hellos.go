@@ -0,0 +1,217 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package pcap
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/netip"
+	"time"
+
+	"src.agwa.name/tlshacks"
+)
+
+// Flow identifies one direction of a TCP connection.  The transport protocol is always TCP.
+type Flow struct {
+	Source      netip.AddrPort `json:"source"`
+	Destination netip.AddrPort `json:"destination"`
+}
+
+func (flow Flow) String() string {
+	return flow.Source.String() + " -> " + flow.Destination.String()
+}
+
+type ClientHello struct {
+	Flow        Flow                      `json:"flow"`
+	Timestamp   time.Time                 `json:"timestamp"`
+	ClientHello *tlshacks.ClientHelloInfo `json:"client_hello"`
+}
+
+const (
+	maxHandshakeBytes  = 128 * 1024 // give up on a flow if the ClientHello is larger than this
+	maxPendingSegments = 64         // give up on a flow if this many segments arrive out of order
+)
+
+type stream struct {
+	started   bool
+	done      bool
+	nextSeq   uint32
+	timestamp time.Time
+	data      []byte
+	pending   map[uint32][]byte
+}
+
+// HelloReader reassembles the TCP flows in a capture and returns the first ClientHello
+// sent in each flow.  Only the beginning of each flow is buffered; once a ClientHello
+// has been found (or the flow turns out not to be TLS), the rest of the flow is ignored.
+type HelloReader struct {
+	reader  *Reader
+	streams map[Flow]*stream
+}
+
+func NewHelloReader(r io.Reader) (*HelloReader, error) {
+	reader, err := NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return &HelloReader{
+		reader:  reader,
+		streams: make(map[Flow]*stream),
+	}, nil
+}
+
+// Next returns the next ClientHello in the capture, or io.EOF if there are no more
+func (hr *HelloReader) Next() (*ClientHello, error) {
+	for {
+		packet, err := hr.reader.ReadPacket()
+		if err != nil {
+			return nil, err
+		}
+		segment, ok := decodePacket(packet)
+		if !ok {
+			continue
+		}
+		if hello := hr.handleSegment(packet.Timestamp, segment); hello != nil {
+			return hello, nil
+		}
+	}
+}
+
+func (hr *HelloReader) handleSegment(timestamp time.Time, segment *tcpSegment) *ClientHello {
+	s := hr.streams[segment.flow]
+	if segment.flags&(tcpFlagFIN|tcpFlagRST) != 0 {
+		delete(hr.streams, segment.flow)
+		if s == nil || s.done {
+			return nil
+		}
+	} else if segment.flags&tcpFlagSYN != 0 {
+		// A SYN starts a new flow, even if we've already seen this 5-tuple
+		s = &stream{started: true, nextSeq: segment.seq + 1}
+		hr.streams[segment.flow] = s
+	} else if s == nil {
+		s = new(stream)
+		hr.streams[segment.flow] = s
+	}
+
+	if s.done || len(segment.data) == 0 {
+		return nil
+	}
+	if !s.started {
+		// We missed the SYN, so assume this segment starts the flow
+		s.started = true
+		s.nextSeq = segment.seq
+	}
+
+	s.addSegment(timestamp, segment.seq, segment.data)
+	hello, err := s.parse()
+	if hello != nil || err != nil {
+		s.done = true
+		s.data = nil
+		s.pending = nil
+	}
+	if hello == nil {
+		return nil
+	}
+	return &ClientHello{
+		Flow:        segment.flow,
+		Timestamp:   s.timestamp,
+		ClientHello: hello,
+	}
+}
+
+func (s *stream) addSegment(timestamp time.Time, seq uint32, data []byte) {
+	if offset := int32(seq - s.nextSeq); offset > 0 {
+		if s.pending == nil {
+			s.pending = make(map[uint32][]byte)
+		}
+		if len(s.pending) < maxPendingSegments {
+			s.pending[seq] = bytes.Clone(data)
+		} else {
+			s.done = true
+		}
+		return
+	} else if -int(offset) >= len(data) {
+		// Retransmission of data we already have
+		return
+	} else {
+		data = data[-offset:]
+	}
+
+	if len(s.data) == 0 {
+		s.timestamp = timestamp
+	}
+	s.data = append(s.data, data...)
+	s.nextSeq += uint32(len(data))
+	s.addPending()
+}
+
+// addPending appends any pending segments which have become contiguous with the data
+func (s *stream) addPending() {
+	for progress := true; progress; {
+		progress = false
+		for seq, data := range s.pending {
+			if offset := int32(seq - s.nextSeq); offset <= 0 {
+				delete(s.pending, seq)
+				if -int(offset) < len(data) {
+					s.data = append(s.data, data[-offset:]...)
+					s.nextSeq += uint32(len(data) + int(offset))
+					progress = true
+				}
+			}
+		}
+	}
+}
+
+var errNotClientHello = errors.New("flow does not start with a ClientHello")
+
+// parse returns the ClientHello if the stream contains a complete one,
+// nil if more data is needed, or an error if the stream doesn't contain a ClientHello
+func (s *stream) parse() (*tlshacks.ClientHelloInfo, error) {
+	if s.done {
+		return nil, errNotClientHello
+	}
+	if len(s.data) == 0 {
+		return nil, nil
+	}
+	if s.data[0] != 22 {
+		return nil, errNotClientHello
+	}
+	message, err := tlshacks.NewHandshakeReader(bytes.NewReader(s.data)).ReadMessage()
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		if len(s.data) > maxHandshakeBytes {
+			return nil, errNotClientHello
+		}
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	hello := tlshacks.UnmarshalClientHello(message)
+	if hello == nil {
+		return nil, errNotClientHello
+	}
+	return hello, nil
+}
@@ -0,0 +1,115 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package pcap
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readHex(t *testing.T, path string) []byte {
+	t.Helper()
+	hexBytes, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := hex.DecodeString(strings.TrimSpace(string(hexBytes)))
+	if err != nil {
+		t.Fatalf("%s: %s", path, err)
+	}
+	return data
+}
+
+// The captures are generated by testdata/generate.go
+func TestHelloReader(t *testing.T) {
+	start := time.Date(2024, time.July, 9, 12, 0, 0, 123456000, time.UTC)
+	expected := []struct {
+		flow      Flow
+		timestamp time.Time
+		hello     []byte
+	}{
+		{
+			flow:      Flow{Source: netip.MustParseAddrPort("192.0.2.1:40000"), Destination: netip.MustParseAddrPort("192.0.2.2:443")},
+			timestamp: start.Add(2 * time.Millisecond),
+			hello:     readHex(t, "../testdata/firefox-128.hex"),
+		},
+		{
+			flow:      Flow{Source: netip.MustParseAddrPort("[2001:db8::1]:40002"), Destination: netip.MustParseAddrPort("[2001:db8::2]:443")},
+			timestamp: start.Add(9 * time.Millisecond),
+			hello:     readHex(t, "../testdata/chrome-133.hex"),
+		},
+	}
+
+	for _, filename := range []string{"hellos.pcap", "hellos.pcapng"} {
+		file, err := os.Open(filepath.Join("testdata", filename))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		reader, err := NewHelloReader(file)
+		if err != nil {
+			t.Fatalf("%s: %s", filename, err)
+		}
+		for i := 0; ; i++ {
+			hello, err := reader.Next()
+			if err == io.EOF {
+				if i != len(expected) {
+					t.Errorf("%s: found %d ClientHellos, expected %d", filename, i, len(expected))
+				}
+				break
+			} else if err != nil {
+				t.Fatalf("%s: %s", filename, err)
+			}
+			if i >= len(expected) {
+				t.Errorf("%s: unexpected ClientHello in flow %s", filename, hello.Flow)
+				continue
+			}
+			if hello.Flow != expected[i].flow {
+				t.Errorf("%s: ClientHello %d is in flow %s, expected %s", filename, i, hello.Flow, expected[i].flow)
+			}
+			if !hello.Timestamp.Equal(expected[i].timestamp) {
+				t.Errorf("%s: ClientHello %d has timestamp %s, expected %s", filename, i, hello.Timestamp, expected[i].timestamp)
+			}
+			if !bytes.Equal(hello.ClientHello.Raw, expected[i].hello) {
+				t.Errorf("%s: ClientHello %d was not reassembled correctly", filename, i)
+			}
+		}
+	}
+}
+
+func TestNewReaderUnknownFormat(t *testing.T) {
+	if _, err := NewReader(strings.NewReader("GET / HTTP/1.1\r\n\r\n")); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("NewReader returned %v, expected ErrUnknownFormat", err)
+	}
+}
@@ -0,0 +1,327 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+// Package pcap reads packet captures in the pcap and pcapng formats and
+// extracts the ClientHellos that they contain.
+package pcap
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Link types from https://www.tcpdump.org/linktypes.html
+const (
+	LinkTypeNull      = 0
+	LinkTypeEthernet  = 1
+	LinkTypeRaw       = 101
+	LinkTypeLinuxSLL  = 113
+	LinkTypeIPv4      = 228
+	LinkTypeIPv6      = 229
+	LinkTypeLinuxSLL2 = 276
+)
+
+type Packet struct {
+	Timestamp time.Time
+	LinkType  uint32
+	Data      []byte
+}
+
+type interfaceInfo struct {
+	linkType          uint32
+	resolution        time.Duration // duration of one timestamp unit (zero if sub-nanosecond)
+	resolutionDivisor uint64        // for sub-nanosecond resolutions
+}
+
+// Reader reads packets from a capture file in either the pcap or pcapng format
+type Reader struct {
+	r         *bufio.Reader
+	byteOrder binary.ByteOrder
+	ng        bool
+
+	// pcap
+	linkType   uint32
+	nanosecond bool
+
+	// pcapng
+	interfaces []interfaceInfo
+}
+
+const (
+	pcapMagicMicroseconds = 0xa1b2c3d4
+	pcapMagicNanoseconds  = 0xa1b23c4d
+	pcapngBlockTypeSHB    = 0x0a0d0d0a
+	pcapngByteOrderMagic  = 0x1a2b3c4d
+
+	pcapngBlockTypeIDB = 1
+	pcapngBlockTypePB  = 2
+	pcapngBlockTypeSPB = 3
+	pcapngBlockTypeEPB = 6
+
+	maxBlockLength = 64 * 1024 * 1024
+)
+
+var ErrUnknownFormat = errors.New("not a pcap or pcapng file")
+
+func NewReader(r io.Reader) (*Reader, error) {
+	reader := &Reader{r: bufio.NewReader(r)}
+	magicBytes, err := reader.r.Peek(4)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case binary.BigEndian.Uint32(magicBytes) == pcapngBlockTypeSHB:
+		reader.ng = true
+		if err := reader.readSectionHeader(); err != nil {
+			return nil, err
+		}
+	case binary.LittleEndian.Uint32(magicBytes) == pcapMagicMicroseconds || binary.LittleEndian.Uint32(magicBytes) == pcapMagicNanoseconds:
+		reader.byteOrder = binary.LittleEndian
+	case binary.BigEndian.Uint32(magicBytes) == pcapMagicMicroseconds || binary.BigEndian.Uint32(magicBytes) == pcapMagicNanoseconds:
+		reader.byteOrder = binary.BigEndian
+	default:
+		return nil, ErrUnknownFormat
+	}
+	if !reader.ng {
+		var header [24]byte
+		if _, err := io.ReadFull(reader.r, header[:]); err != nil {
+			return nil, noEOF(err)
+		}
+		reader.nanosecond = reader.byteOrder.Uint32(header[0:4]) == pcapMagicNanoseconds
+		reader.linkType = reader.byteOrder.Uint32(header[20:24]) & 0x0FFFFFFF
+	}
+	return reader, nil
+}
+
+// ReadPacket returns the next packet in the capture, or io.EOF if there are no more packets
+func (reader *Reader) ReadPacket() (*Packet, error) {
+	if reader.ng {
+		return reader.readNGPacket()
+	}
+
+	var header [16]byte
+	if _, err := io.ReadFull(reader.r, header[:]); err != nil {
+		return nil, err
+	}
+	var (
+		seconds        = reader.byteOrder.Uint32(header[0:4])
+		fraction       = reader.byteOrder.Uint32(header[4:8])
+		capturedLength = reader.byteOrder.Uint32(header[8:12])
+	)
+	if capturedLength > maxBlockLength {
+		return nil, fmt.Errorf("packet length %d is too large", capturedLength)
+	}
+	if !reader.nanosecond {
+		fraction *= 1000
+	}
+	packet := &Packet{
+		Timestamp: time.Unix(int64(seconds), int64(fraction)),
+		LinkType:  reader.linkType,
+		Data:      make([]byte, capturedLength),
+	}
+	if _, err := io.ReadFull(reader.r, packet.Data); err != nil {
+		return nil, noEOF(err)
+	}
+	return packet, nil
+}
+
+func (reader *Reader) readBlock() (uint32, []byte, error) {
+	var header [8]byte
+	if _, err := io.ReadFull(reader.r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	blockType := reader.byteOrder.Uint32(header[0:4])
+	blockLength := reader.byteOrder.Uint32(header[4:8])
+	if blockLength < 12 || blockLength%4 != 0 || blockLength > maxBlockLength {
+		return 0, nil, fmt.Errorf("pcapng block has invalid length %d", blockLength)
+	}
+	body := make([]byte, blockLength-8)
+	if _, err := io.ReadFull(reader.r, body); err != nil {
+		return 0, nil, noEOF(err)
+	}
+	// The trailing copy of the block length is not needed
+	return blockType, body[:len(body)-4], nil
+}
+
+func (reader *Reader) readSectionHeader() error {
+	var header [12]byte
+	if _, err := io.ReadFull(reader.r, header[:]); err != nil {
+		return noEOF(err)
+	}
+	switch {
+	case binary.LittleEndian.Uint32(header[8:12]) == pcapngByteOrderMagic:
+		reader.byteOrder = binary.LittleEndian
+	case binary.BigEndian.Uint32(header[8:12]) == pcapngByteOrderMagic:
+		reader.byteOrder = binary.BigEndian
+	default:
+		return ErrUnknownFormat
+	}
+	blockLength := reader.byteOrder.Uint32(header[4:8])
+	if blockLength < 28 || blockLength%4 != 0 || blockLength > maxBlockLength {
+		return fmt.Errorf("pcapng section header has invalid length %d", blockLength)
+	}
+	if _, err := io.CopyN(io.Discard, reader.r, int64(blockLength)-12); err != nil {
+		return noEOF(err)
+	}
+	// Interface IDs are scoped to the section
+	reader.interfaces = nil
+	return nil
+}
+
+func (reader *Reader) parseInterfaceDescription(body []byte) error {
+	if len(body) < 8 {
+		return errors.New("pcapng interface description block is too short")
+	}
+	iface := interfaceInfo{
+		linkType:   uint32(reader.byteOrder.Uint16(body[0:2])),
+		resolution: time.Microsecond,
+	}
+	options := body[8:]
+	for len(options) >= 4 {
+		code := reader.byteOrder.Uint16(options[0:2])
+		length := int(reader.byteOrder.Uint16(options[2:4]))
+		if 4+length > len(options) {
+			break
+		}
+		value := options[4 : 4+length]
+		if code == 0 {
+			break
+		} else if code == 9 && length == 1 {
+			iface.resolution, iface.resolutionDivisor = parseResolution(value[0])
+		}
+		options = options[min(len(options), 4+(length+3)&^3):]
+	}
+	reader.interfaces = append(reader.interfaces, iface)
+	return nil
+}
+
+// parseResolution parses the if_tsresol option
+func parseResolution(tsresol uint8) (time.Duration, uint64) {
+	exponent := uint64(tsresol & 0x7F)
+	var unitsPerSecond uint64 = 1
+	for i := uint64(0); i < exponent; i++ {
+		if tsresol&0x80 != 0 {
+			unitsPerSecond *= 2
+		} else {
+			unitsPerSecond *= 10
+		}
+		if unitsPerSecond > 1e18 {
+			break
+		}
+	}
+	if unitsPerSecond <= 1e9 && 1e9%unitsPerSecond == 0 {
+		return time.Duration(1e9 / unitsPerSecond), 0
+	}
+	return 0, unitsPerSecond
+}
+
+func (iface *interfaceInfo) timestamp(units uint64) time.Time {
+	if iface.resolution != 0 {
+		seconds := units / uint64(time.Second/iface.resolution)
+		remainder := units % uint64(time.Second/iface.resolution)
+		return time.Unix(int64(seconds), int64(remainder)*int64(iface.resolution))
+	}
+	seconds := units / iface.resolutionDivisor
+	remainder := units % iface.resolutionDivisor
+	return time.Unix(int64(seconds), int64(float64(remainder)*1e9/float64(iface.resolutionDivisor)))
+}
+
+func (reader *Reader) readNGPacket() (*Packet, error) {
+	for {
+		peeked, err := reader.r.Peek(4)
+		if err != nil {
+			return nil, err
+		}
+		if binary.BigEndian.Uint32(peeked) == pcapngBlockTypeSHB {
+			if err := reader.readSectionHeader(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		blockType, body, err := reader.readBlock()
+		if err != nil {
+			return nil, err
+		}
+		switch blockType {
+		case pcapngBlockTypeIDB:
+			if err := reader.parseInterfaceDescription(body); err != nil {
+				return nil, err
+			}
+		case pcapngBlockTypeEPB, pcapngBlockTypePB:
+			if len(body) < 20 {
+				return nil, errors.New("pcapng packet block is too short")
+			}
+			var interfaceID uint32
+			if blockType == pcapngBlockTypeEPB {
+				interfaceID = reader.byteOrder.Uint32(body[0:4])
+			} else {
+				interfaceID = uint32(reader.byteOrder.Uint16(body[0:2]))
+			}
+			if interfaceID >= uint32(len(reader.interfaces)) {
+				return nil, fmt.Errorf("pcapng packet block references unknown interface %d", interfaceID)
+			}
+			iface := &reader.interfaces[interfaceID]
+			units := uint64(reader.byteOrder.Uint32(body[4:8]))<<32 | uint64(reader.byteOrder.Uint32(body[8:12]))
+			capturedLength := reader.byteOrder.Uint32(body[12:16])
+			if uint64(capturedLength) > uint64(len(body)-20) {
+				return nil, errors.New("pcapng packet block has invalid captured length")
+			}
+			return &Packet{
+				Timestamp: iface.timestamp(units),
+				LinkType:  iface.linkType,
+				Data:      body[20 : 20+capturedLength],
+			}, nil
+		case pcapngBlockTypeSPB:
+			if len(body) < 4 {
+				return nil, errors.New("pcapng simple packet block is too short")
+			}
+			if len(reader.interfaces) == 0 {
+				return nil, errors.New("pcapng simple packet block appears before interface description")
+			}
+			originalLength := reader.byteOrder.Uint32(body[0:4])
+			data := body[4:]
+			if uint64(originalLength) < uint64(len(data)) {
+				data = data[:originalLength]
+			}
+			// Simple packet blocks don't have timestamps
+			return &Packet{
+				LinkType: reader.interfaces[0].linkType,
+				Data:     data,
+			}, nil
+		}
+	}
+}
+
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
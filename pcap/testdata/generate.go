@@ -0,0 +1,208 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+//go:build generate
+
+// This program generates the captures in this directory, which contain the
+// ClientHellos from ../../testdata split across TCP segments.  Run it from
+// the pcap directory with `go run testdata/generate.go`.
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"log"
+	"net/netip"
+	"os"
+	"strings"
+	"time"
+)
+
+type packet struct {
+	timestamp time.Time
+	data      []byte
+}
+
+func readHello(path string) []byte {
+	hexBytes, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	hello, err := hex.DecodeString(strings.TrimSpace(string(hexBytes)))
+	if err != nil {
+		log.Fatalf("%s: %s", path, err)
+	}
+	return hello
+}
+
+// records splits a handshake message into TLS records of at most fragmentLength bytes
+func records(message []byte, fragmentLength int) []byte {
+	var out []byte
+	for len(message) > 0 {
+		fragment := message[:min(len(message), fragmentLength)]
+		message = message[len(fragment):]
+		out = append(out, 22, 3, 1)
+		out = binary.BigEndian.AppendUint16(out, uint16(len(fragment)))
+		out = append(out, fragment...)
+	}
+	return out
+}
+
+func tcp(src, dst netip.AddrPort, seq uint32, flags uint8, payload []byte) []byte {
+	segment := binary.BigEndian.AppendUint16(nil, src.Port())
+	segment = binary.BigEndian.AppendUint16(segment, dst.Port())
+	segment = binary.BigEndian.AppendUint32(segment, seq)
+	segment = binary.BigEndian.AppendUint32(segment, 0)     // acknowledgment number
+	segment = append(segment, 5<<4, flags)                  // data offset, flags
+	segment = binary.BigEndian.AppendUint16(segment, 65535) // window
+	segment = append(segment, 0, 0, 0, 0)                   // checksum, urgent pointer
+	return append(segment, payload...)
+}
+
+// ethernet returns an Ethernet frame containing an IP packet with the given TCP segment
+func ethernet(src, dst netip.AddrPort, seq uint32, flags uint8, payload []byte) []byte {
+	segment := tcp(src, dst, seq, flags, payload)
+	frame := []byte{0x02, 0, 0, 0, 0, 2, 0x02, 0, 0, 0, 0, 1}
+	if src.Addr().Is4() {
+		frame = binary.BigEndian.AppendUint16(frame, 0x0800)
+		frame = append(frame, 0x45, 0)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(20+len(segment)))
+		frame = append(frame, 0, 0, 0x40, 0, 64, 6, 0, 0) // identification, don't fragment, TTL, protocol, checksum
+		frame = append(frame, src.Addr().AsSlice()...)
+		frame = append(frame, dst.Addr().AsSlice()...)
+	} else {
+		frame = binary.BigEndian.AppendUint16(frame, 0x86DD)
+		frame = append(frame, 0x60, 0, 0, 0)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(len(segment)))
+		frame = append(frame, 6, 64) // next header, hop limit
+		frame = append(frame, src.Addr().AsSlice()...)
+		frame = append(frame, dst.Addr().AsSlice()...)
+	}
+	return append(frame, segment...)
+}
+
+const (
+	flagFIN = 0x01
+	flagSYN = 0x02
+	flagACK = 0x10
+)
+
+func makePackets() []packet {
+	firefox := records(readHello("../testdata/firefox-128.hex"), 16384)
+	chrome := records(readHello("../testdata/chrome-133.hex"), 1000) // the handshake message spans two records
+
+	client1 := netip.MustParseAddrPort("192.0.2.1:40000")
+	client2 := netip.MustParseAddrPort("192.0.2.3:40001")
+	client3 := netip.MustParseAddrPort("[2001:db8::1]:40002")
+	server := netip.MustParseAddrPort("192.0.2.2:443")
+	server6 := netip.MustParseAddrPort("[2001:db8::2]:443")
+
+	seq3 := uint32(0xFFFFFF00)
+
+	start := time.Date(2024, time.July, 9, 12, 0, 0, 123456000, time.UTC)
+	frames := [][]byte{
+		// The first flow's ClientHello arrives out of order, with a retransmission
+		ethernet(client1, server, 1000, flagSYN, nil),
+		ethernet(server, client1, 5000, flagSYN|flagACK, nil),
+		ethernet(client1, server, 1001, flagACK, firefox[:100]),
+		ethernet(client1, server, 1301, flagACK, firefox[300:]),
+		ethernet(client1, server, 1001, flagACK, firefox[:100]),
+		ethernet(client1, server, 1101, flagACK, firefox[100:300]),
+
+		// The second flow isn't TLS, and its SYN wasn't captured
+		ethernet(client2, server, 7000, flagACK, []byte("GET / HTTP/1.1\r\nHost: example.com\r\n\r\n")),
+		ethernet(client2, server, 7038, flagACK|flagFIN, nil),
+
+		// The third flow is over IPv6, and its sequence numbers wrap around
+		ethernet(client3, server6, seq3, flagSYN, nil),
+		ethernet(client3, server6, seq3+1, flagACK, chrome[:1400]),
+		ethernet(client3, server6, seq3+1+1400, flagACK, chrome[1400:]),
+	}
+	packets := make([]packet, len(frames))
+	for i, frame := range frames {
+		packets[i] = packet{timestamp: start.Add(time.Duration(i) * time.Millisecond), data: frame}
+	}
+	return packets
+}
+
+func writePcap(packets []packet) []byte {
+	var out bytes.Buffer
+	header := []uint32{0xa1b2c3d4, 2 | 4<<16, 0, 0, 65535, 1} // magic, version 2.4, thiszone, sigfigs, snaplen, Ethernet
+	binary.Write(&out, binary.LittleEndian, header)
+	for _, p := range packets {
+		micros := p.timestamp.UnixMicro()
+		binary.Write(&out, binary.LittleEndian, []uint32{uint32(micros / 1e6), uint32(micros % 1e6), uint32(len(p.data)), uint32(len(p.data))})
+		out.Write(p.data)
+	}
+	return out.Bytes()
+}
+
+func pcapngBlock(out *bytes.Buffer, blockType uint32, body []byte) {
+	for len(body)%4 != 0 {
+		body = append(body, 0)
+	}
+	length := uint32(12 + len(body))
+	binary.Write(out, binary.BigEndian, []uint32{blockType, length})
+	out.Write(body)
+	binary.Write(out, binary.BigEndian, length)
+}
+
+func writePcapng(packets []packet) []byte {
+	var out bytes.Buffer
+
+	// Big-endian, to exercise byte order detection
+	shb := binary.BigEndian.AppendUint32(nil, 0x1a2b3c4d)
+	shb = append(shb, 0, 1, 0, 0)                                     // version 1.0
+	shb = append(shb, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff) // unknown section length
+	pcapngBlock(&out, 0x0a0d0d0a, shb)
+
+	idb := []byte{0, 1, 0, 0, 0, 0, 0xff, 0xff} // Ethernet, reserved, snaplen
+	idb = append(idb, 0, 9, 0, 1, 9, 0, 0, 0)   // if_tsresol: nanoseconds
+	idb = append(idb, 0, 0, 0, 0)               // opt_endofopt
+	pcapngBlock(&out, 1, idb)
+
+	for _, p := range packets {
+		nanos := uint64(p.timestamp.UnixNano())
+		epb := binary.BigEndian.AppendUint32(nil, 0) // interface ID
+		epb = binary.BigEndian.AppendUint32(epb, uint32(nanos>>32))
+		epb = binary.BigEndian.AppendUint32(epb, uint32(nanos))
+		epb = binary.BigEndian.AppendUint32(epb, uint32(len(p.data)))
+		epb = binary.BigEndian.AppendUint32(epb, uint32(len(p.data)))
+		epb = append(epb, p.data...)
+		pcapngBlock(&out, 6, epb)
+	}
+	return out.Bytes()
+}
+
+func main() {
+	packets := makePackets()
+	if err := os.WriteFile("testdata/hellos.pcap", writePcap(packets), 0666); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("testdata/hellos.pcapng", writePcapng(packets), 0666); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -89,3 +89,17 @@ func (reader *HandshakeReader) ReadMessage() ([]byte, error) {
 	}
 	return message, nil
 }
+
+const maxRecordLength = 16384
+
+// MakeHandshakeRecords wraps a handshake message in one or more handshake records
+func MakeHandshakeRecords(message []byte) []byte {
+	records := make([]byte, 0, len(message)+5*(len(message)/maxRecordLength+1))
+	for len(message) > 0 {
+		fragment := message[:min(len(message), maxRecordLength)]
+		message = message[len(fragment):]
+		records = append(records, 22, 0x03, 0x01, byte(len(fragment)>>8), byte(len(fragment)))
+		records = append(records, fragment...)
+	}
+	return records
+}
@@ -0,0 +1,135 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package tlshacks
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+)
+
+const (
+	recordTypeChangeCipherSpec = 20
+	recordTypeAlert            = 21
+	recordTypeHandshake        = 22
+)
+
+// ReplayResult is the server's response to a replayed ClientHello.  At least one of
+// ServerHello or Alert is non-nil.
+type ReplayResult struct {
+	ServerHello *ServerHelloInfo `json:"server_hello,omitempty"`
+
+	// In TLS 1.2 and below, the rest of the server's first flight (Certificate,
+	// ServerKeyExchange, etc.) is not encrypted, and is returned here
+	Messages []HandshakeMessage `json:"messages,omitempty"`
+
+	// Abbreviated is true if the server resumed a TLS 1.2 (or earlier) session, in
+	// which case its first flight ends with ChangeCipherSpec instead of ServerHelloDone
+	Abbreviated bool `json:"abbreviated,omitempty"`
+
+	Alert *Alert `json:"alert,omitempty"`
+}
+
+// Replay sends clientHello, which is a handshake message, over conn verbatim, and returns
+// the server's response.  The caller is responsible for setting deadlines and closing conn.
+func Replay(conn net.Conn, clientHello []byte) (*ReplayResult, error) {
+	if _, err := conn.Write(MakeHandshakeRecords(clientHello)); err != nil {
+		return nil, err
+	}
+	return ReadServerResponse(conn)
+}
+
+// ReadServerResponse reads records from r until it encounters a ServerHello (or
+// HelloRetryRequest) or an alert.  If the ServerHello negotiates TLS 1.2 or below,
+// it continues reading until the ServerHelloDone message, or until ChangeCipherSpec
+// if the server resumes a session.
+func ReadServerResponse(r io.Reader) (*ReplayResult, error) {
+	result := new(ReplayResult)
+	var handshake []byte
+	for {
+		header, err := readRecordHeader(r)
+		if err == io.EOF && result.ServerHello == nil {
+			return nil, errors.New("server closed the connection without responding")
+		} else if err == io.EOF {
+			return nil, errors.New("server closed the connection before sending ServerHelloDone")
+		} else if err != nil {
+			return nil, err
+		}
+		body := make([]byte, header.length)
+		if _, err := io.ReadFull(r, body); err != nil {
+			return nil, err
+		}
+
+		switch header.contentType {
+		case recordTypeAlert:
+			if len(body) != 2 {
+				return nil, fmt.Errorf("server sent malformed alert record")
+			}
+			result.Alert = MakeAlert(body[0], body[1])
+			return result, nil
+		case recordTypeChangeCipherSpec:
+			if result.ServerHello == nil {
+				return nil, errors.New("server sent ChangeCipherSpec before ServerHello")
+			}
+			// The rest of an abbreviated handshake is encrypted
+			result.Abbreviated = true
+			return result, nil
+		case recordTypeHandshake:
+			handshake = append(handshake, body...)
+			for len(handshake) >= 4 {
+				length := 4 + (int(handshake[1])<<16 | int(handshake[2])<<8 | int(handshake[3]))
+				if len(handshake) < length {
+					break
+				}
+				message := handshake[:length]
+				handshake = handshake[length:]
+				if result.ServerHello != nil {
+					result.Messages = append(result.Messages, MakeHandshakeMessage(message))
+					if message[0] == 14 { // server_hello_done
+						return result, nil
+					}
+					continue
+				}
+				if message[0] != 2 {
+					return nil, fmt.Errorf("server sent handshake message of type %d instead of ServerHello", message[0])
+				}
+				result.ServerHello = UnmarshalServerHello(message)
+				if result.ServerHello == nil {
+					return nil, errors.New("server sent malformed ServerHello")
+				}
+				if result.ServerHello.Info.HelloRetryRequest || result.ServerHello.Info.Version >= 0x0304 {
+					return result, nil
+				}
+			}
+		default:
+			if result.ServerHello == nil {
+				return nil, fmt.Errorf("server sent record of type %d before ServerHello", header.contentType)
+			}
+			return nil, fmt.Errorf("server sent record of type %d before ServerHelloDone", header.contentType)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package tlshacks
+
+import (
+	"crypto/tls"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func newReplayServer(t *testing.T, config *tls.Config) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.NotFoundHandler())
+	server.TLS = config
+	server.Config.ErrorLog = log.New(io.Discard, "", 0) // the replayed handshakes are never completed
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func replay(t *testing.T, server *httptest.Server, builder *ClientHelloBuilder) *ReplayResult {
+	t.Helper()
+	clientHello, err := builder.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	result, err := Replay(conn, clientHello)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestReplayTLS13(t *testing.T) {
+	server := newReplayServer(t, &tls.Config{CurvePreferences: []tls.CurveID{tls.X25519}})
+	result := replay(t, server, &ClientHelloBuilder{
+		CipherSuites: []uint16{0x1302, 0x1301},
+		Extensions: []BuilderExtension{
+			SupportedGroupsExtension(29),
+			SignatureAlgorithmsExtension(0x0804),
+			KeyShareExtension(KeyShareEntry{Group: 29}),
+		},
+	})
+	if result.Alert != nil {
+		t.Fatalf("server sent alert %s", result.Alert.Name)
+	}
+	serverHello := result.ServerHello
+	if serverHello.Info.Version != 0x0304 || serverHello.Info.HelloRetryRequest {
+		t.Errorf("server negotiated %s (HelloRetryRequest = %t), expected TLS 1.3", serverHello.Info.Version, serverHello.Info.HelloRetryRequest)
+	}
+	if suite := serverHello.CipherSuite.CodeUint16(); suite != 0x1302 && suite != 0x1301 {
+		t.Errorf("server selected cipher suite %04x, which wasn't offered", suite)
+	}
+	if serverHello.Info.Group == nil || *serverHello.Info.Group != 29 {
+		t.Errorf("server selected group %v, expected x25519", serverHello.Info.Group)
+	}
+	if len(result.Messages) != 0 {
+		t.Errorf("result contains %d messages after the ServerHello, which should be encrypted", len(result.Messages))
+	}
+}
+
+func TestReplayTLS12(t *testing.T) {
+	server := newReplayServer(t, &tls.Config{MaxVersion: tls.VersionTLS12})
+	result := replay(t, server, &ClientHelloBuilder{
+		MaxVersion:   0x0303,
+		CipherSuites: []uint16{0xc02f},
+		Extensions: []BuilderExtension{
+			SupportedGroupsExtension(23),
+			ECPointFormatsExtension(0),
+			SignatureAlgorithmsExtension(0x0804, 0x0401),
+			RenegotiationInfoExtension(),
+		},
+	})
+	if result.Alert != nil {
+		t.Fatalf("server sent alert %s", result.Alert.Name)
+	}
+	if result.ServerHello.Info.Version != 0x0303 || result.ServerHello.CipherSuite.CodeUint16() != 0xc02f {
+		t.Errorf("server negotiated %s with cipher suite %04x, expected TLS 1.2 with c02f", result.ServerHello.Info.Version, result.ServerHello.CipherSuite.CodeUint16())
+	}
+	var types []uint8
+	for _, message := range result.Messages {
+		types = append(types, message.Type)
+	}
+	if expected := []uint8{11, 12, 14}; !slices.Equal(types, expected) { // certificate, server_key_exchange, server_hello_done
+		t.Errorf("server's first flight has message types %v, expected %v", types, expected)
+	}
+}
+
+func TestReplayAlert(t *testing.T) {
+	server := newReplayServer(t, &tls.Config{})
+	result := replay(t, server, &ClientHelloBuilder{
+		MaxVersion:   0x0303,
+		CipherSuites: []uint16{0x0005}, // TLS_RSA_WITH_RC4_128_SHA isn't supported by crypto/tls
+		Extensions:   []BuilderExtension{SignatureAlgorithmsExtension(0x0401)},
+	})
+	if result.ServerHello != nil {
+		t.Fatalf("server sent ServerHello with cipher suite %04x", result.ServerHello.CipherSuite.CodeUint16())
+	}
+	if result.Alert == nil || result.Alert.Level != 2 || result.Alert.Description != 40 {
+		t.Errorf("server sent alert %+v, expected fatal handshake_failure", result.Alert)
+	}
+}
+
+// ticketCache is a tls.ClientSessionCache which remembers the most recent session
+type ticketCache struct {
+	session *tls.ClientSessionState
+}
+
+func (c *ticketCache) Get(string) (*tls.ClientSessionState, bool) { return c.session, c.session != nil }
+func (c *ticketCache) Put(_ string, session *tls.ClientSessionState) {
+	c.session = session
+}
+
+func TestReplayResumption(t *testing.T) {
+	server := newReplayServer(t, &tls.Config{MaxVersion: tls.VersionTLS12})
+
+	// Obtain a session ticket with a full handshake
+	cache := new(ticketCache)
+	conn, err := tls.Dial("tcp", server.Listener.Addr().String(), &tls.Config{
+		InsecureSkipVerify: true,
+		MaxVersion:         tls.VersionTLS12,
+		CipherSuites:       []uint16{0xc02f},
+		ClientSessionCache: cache,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	if cache.session == nil {
+		t.Fatal("server did not issue a session ticket")
+	}
+	ticket, _, err := cache.session.ResumptionState()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := replay(t, server, &ClientHelloBuilder{
+		MaxVersion:   0x0303,
+		CipherSuites: []uint16{0xc02f},
+		Extensions: []BuilderExtension{
+			SupportedGroupsExtension(29, 23),
+			ECPointFormatsExtension(0),
+			SignatureAlgorithmsExtension(0x0804, 0x0401),
+			RenegotiationInfoExtension(),
+			EmptyExtension(23), // extended_master_secret, which crypto/tls requires to resume a session that used it
+			{Type: 35, Data: ticket},
+		},
+	})
+	if result.Alert != nil {
+		t.Fatalf("server sent alert %s", result.Alert.Name)
+	}
+	if result.ServerHello.Info.Version != 0x0303 {
+		t.Errorf("server negotiated %s, expected TLS 1.2", result.ServerHello.Info.Version)
+	}
+	if !result.Abbreviated {
+		t.Error("server did not resume the session")
+	}
+	for _, message := range result.Messages {
+		if message.Type != 4 { // new_session_ticket
+			t.Errorf("server sent %s in an abbreviated handshake", message.Name)
+		}
+	}
+}
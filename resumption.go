@@ -0,0 +1,81 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package tlshacks
+
+import (
+	"slices"
+)
+
+// Values of Info.Resumption
+const (
+	ResumptionNone      = "none"
+	ResumptionSessionID = "session_id" // TLS 1.2 and below session ID
+	ResumptionTicket    = "ticket"     // TLS 1.2 and below session ticket (RFC 5077)
+	ResumptionPSK       = "psk"        // TLS 1.3 pre-shared key
+	Resumption0RTT      = "0rtt"       // TLS 1.3 pre-shared key with early data
+)
+
+// ResumptionType classifies the kind of session resumption attempted by the ClientHello.
+//
+// Clients which offer TLS 1.3 usually send a random legacy_session_id for middlebox compatibility
+// (RFC 8446, Appendix D.4), so a non-empty session ID is considered a resumption attempt only if
+// TLS 1.3 isn't offered.
+func ResumptionType(hello *ClientHelloInfo) string {
+	if ext := hello.Extension(41); ext != nil {
+		if data, ok := ext.Data.(*PreSharedKeyData); ok && len(data.Identities) > 0 {
+			if hello.Extension(42) != nil {
+				return Resumption0RTT
+			}
+			return ResumptionPSK
+		}
+	}
+	if ext := hello.Extension(35); ext != nil {
+		if data, ok := ext.Data.(*SessionTicketData); ok && len(data.Ticket) > 0 {
+			return ResumptionTicket
+		}
+	}
+	if len(hello.SessionID) > 0 && !slices.Contains(supportedVersionsOf(hello), 0x0304) {
+		return ResumptionSessionID
+	}
+	return ResumptionNone
+}
+
+// SessionTicket returns the ticket which the client is attempting to resume: the first
+// pre_shared_key identity, or else the contents of the session_ticket extension.  It
+// returns nil if the client isn't attempting to resume with a ticket.
+func (info *ClientHelloInfo) SessionTicket() []byte {
+	if ext := info.Extension(41); ext != nil {
+		if data, ok := ext.Data.(*PreSharedKeyData); ok && len(data.Identities) > 0 {
+			return data.Identities[0].Identity
+		}
+	}
+	if ext := info.Extension(35); ext != nil {
+		if data, ok := ext.Data.(*SessionTicketData); ok && len(data.Ticket) > 0 {
+			return data.Ticket
+		}
+	}
+	return nil
+}
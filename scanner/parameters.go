@@ -0,0 +1,104 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package scanner
+
+import (
+	"crypto/ecdh"
+	"crypto/mlkem"
+	"crypto/rand"
+
+	"src.agwa.name/tlshacks"
+)
+
+// groupCandidates are offered in roughly the order a modern client prefers them, so that
+// the key shares sent by default are the ones most likely to be accepted
+var groupCandidates = []uint16{29, 23, 0x11ec, 24, 25, 30, 0x11eb, 0x11ed, 0x6399, 22, 21, 26, 27, 28, 31, 32, 33, 256, 257, 258, 259, 260}
+
+var signatureAlgorithmCandidates = []uint16{0x0403, 0x0804, 0x0401, 0x0503, 0x0805, 0x0501, 0x0603, 0x0806, 0x0601, 0x0807, 0x0808, 0x0809, 0x080a, 0x080b, 0x081a, 0x081b, 0x081c, 0x0904, 0x0905, 0x0906, 0x0301, 0x0303, 0x0201, 0x0203}
+
+type parameterKind int
+
+const (
+	groupParameter parameterKind = iota
+	signatureAlgorithmParameter
+)
+
+func (kind parameterKind) candidates() []uint16 {
+	if kind == groupParameter {
+		return groupCandidates
+	}
+	return signatureAlgorithmCandidates
+}
+
+func (kind parameterKind) parameters(codes []uint16) []Parameter {
+	names := tlshacks.GroupNames
+	if kind == signatureAlgorithmParameter {
+		names = tlshacks.SignatureAlgorithmNames
+	}
+	parameters := []Parameter{}
+	for _, code := range codes {
+		parameters = append(parameters, Parameter{Code: code, Name: names[code]})
+	}
+	return parameters
+}
+
+// keyShare returns a valid key share for the given group, or nil if this package can't generate one.
+// The private key is discarded, since the scanner never completes a handshake.
+func keyShare(group uint16) *tlshacks.KeyShareEntry {
+	ecdhPublicKey := func(curve ecdh.Curve) []byte {
+		key, err := curve.GenerateKey(rand.Reader)
+		if err != nil {
+			panic(err)
+		}
+		return key.PublicKey().Bytes()
+	}
+	mlkem768PublicKey := func() []byte {
+		key, err := mlkem.GenerateKey768()
+		if err != nil {
+			panic(err)
+		}
+		return key.EncapsulationKey().Bytes()
+	}
+
+	var keyExchange []byte
+	switch group {
+	case 23:
+		keyExchange = ecdhPublicKey(ecdh.P256())
+	case 24:
+		keyExchange = ecdhPublicKey(ecdh.P384())
+	case 25:
+		keyExchange = ecdhPublicKey(ecdh.P521())
+	case 29:
+		keyExchange = ecdhPublicKey(ecdh.X25519())
+	case 0x11eb:
+		keyExchange = append(ecdhPublicKey(ecdh.P256()), mlkem768PublicKey()...)
+	case 0x11ec:
+		keyExchange = append(mlkem768PublicKey(), ecdhPublicKey(ecdh.X25519())...)
+	default:
+		return nil
+	}
+	return &tlshacks.KeyShareEntry{Group: group, KeyExchange: keyExchange}
+}
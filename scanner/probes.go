@@ -0,0 +1,409 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package scanner
+
+import (
+	"crypto/rand"
+	"crypto/tls"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/cryptobyte"
+	"src.agwa.name/tlshacks"
+)
+
+func setExtension(builder *tlshacks.ClientHelloBuilder, ext tlshacks.BuilderExtension) {
+	for i := range builder.Extensions {
+		if builder.Extensions[i].Type == ext.Type {
+			builder.Extensions[i] = ext
+			return
+		}
+	}
+	builder.Extensions = append(builder.Extensions, ext)
+}
+
+func (report *Report) tls13CipherSuites() []uint16 {
+	var suites []uint16
+	for _, v := range report.Versions {
+		if v.Version == 0x0304 {
+			for _, suite := range v.CipherSuites {
+				suites = append(suites, suite.CodeUint16())
+			}
+		}
+	}
+	return suites
+}
+
+// scanTLS13Groups offers every candidate group without any key shares, so that the server
+// responds with a HelloRetryRequest naming its preferred group, which is then removed from
+// the offer.  If the server doesn't send HelloRetryRequests, each group is instead offered
+// on its own with a key share.
+func (s *scanner) scanTLS13Groups(cipherSuites []uint16) ([]Parameter, error) {
+	remaining := slices.Clone(groupCandidates)
+	var found []uint16
+	for len(remaining) > 0 {
+		builder := s.builder(0x0304, cipherSuites, remaining, signatureAlgorithmCandidates)
+		setExtension(builder, tlshacks.KeyShareExtension())
+		result, err := s.probe(builder)
+		if err == errRejected {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if _, ok := negotiated(result, 0x0304); !ok || result.ServerHello.Info.Group == nil {
+			break
+		}
+		group := *result.ServerHello.Info.Group
+		if !slices.Contains(remaining, group) {
+			break
+		}
+		found = append(found, group)
+		remaining = slices.DeleteFunc(remaining, func(g uint16) bool { return g == group })
+	}
+	if len(found) > 0 {
+		return groupParameter.parameters(found), nil
+	}
+
+	for _, group := range groupCandidates {
+		share := keyShare(group)
+		if share == nil {
+			share = &tlshacks.KeyShareEntry{Group: group}
+		}
+		builder := s.builder(0x0304, cipherSuites, []uint16{group}, signatureAlgorithmCandidates)
+		setExtension(builder, tlshacks.KeyShareExtension(*share))
+		result, err := s.probe(builder)
+		if err == errRejected {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if _, ok := negotiated(result, 0x0304); ok && !result.ServerHello.Info.HelloRetryRequest {
+			found = append(found, group)
+		}
+	}
+	return groupParameter.parameters(found), nil
+}
+
+// scanTLS13SignatureAlgorithms offers each candidate signature algorithm on its own.  The
+// server has to choose a signature algorithm before sending its ServerHello, so receiving
+// a ServerHello means the algorithm is usable with one of the server's certificates.
+func (s *scanner) scanTLS13SignatureAlgorithms(cipherSuites []uint16, groups []Parameter) ([]Parameter, error) {
+	groupCodes := slices.Clone(groupCandidates)
+	if len(groups) > 0 {
+		// Put the server's preferred group first so it gets a key share
+		groupCodes = slices.DeleteFunc(groupCodes, func(g uint16) bool { return g == groups[0].Code })
+		groupCodes = slices.Insert(groupCodes, 0, groups[0].Code)
+	}
+	var found []uint16
+	for _, algorithm := range signatureAlgorithmCandidates {
+		result, err := s.probe(s.builder(0x0304, cipherSuites, groupCodes, []uint16{algorithm}))
+		if err == errRejected {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if _, ok := negotiated(result, 0x0304); ok && !result.ServerHello.Info.HelloRetryRequest {
+			found = append(found, algorithm)
+		}
+	}
+	return signatureAlgorithmParameter.parameters(found), nil
+}
+
+type serverKeyExchange struct {
+	group              *uint16
+	signatureAlgorithm *uint16
+}
+
+// parseServerKeyExchange parses the parameters of an (EC)DHE ServerKeyExchange message (RFC 4492,
+// Section 5.4 and RFC 5246, Section 7.4.3).  Only TLS 1.2 messages contain a signature algorithm.
+func parseServerKeyExchange(version tlshacks.ProtocolVersion, suite tlshacks.CipherSuite, body []byte) *serverKeyExchange {
+	ske := new(serverKeyExchange)
+	s := cryptobyte.String(body)
+	switch {
+	case strings.Contains(suite.Name, "_ECDHE_"):
+		var curveType uint8
+		var group uint16
+		var point cryptobyte.String
+		if !s.ReadUint8(&curveType) || curveType != 3 || !s.ReadUint16(&group) || !s.ReadUint8LengthPrefixed(&point) {
+			return nil
+		}
+		ske.group = &group
+	case strings.Contains(suite.Name, "_DHE_"):
+		var p, g, y cryptobyte.String
+		if !s.ReadUint16LengthPrefixed(&p) || !s.ReadUint16LengthPrefixed(&g) || !s.ReadUint16LengthPrefixed(&y) {
+			return nil
+		}
+	default:
+		return nil
+	}
+	if version >= 0x0303 {
+		var algorithm uint16
+		if !s.ReadUint16(&algorithm) {
+			return nil
+		}
+		ske.signatureAlgorithm = &algorithm
+	}
+	return ske
+}
+
+func isSignedKeyExchange(suite tlshacks.CipherSuite) bool {
+	for _, kx := range []string{"_ECDHE_ECDSA_", "_ECDHE_RSA_", "_DHE_RSA_", "_DHE_DSS_"} {
+		if strings.HasPrefix(suite.Name, "TLS"+kx) {
+			return true
+		}
+	}
+	return false
+}
+
+// scanServerKeyExchange enumerates the groups or signature algorithms selected by a TLS 1.2 or
+// below server, as revealed in its ServerKeyExchange message, by repeatedly removing the selected
+// value from the offer
+func (s *scanner) scanServerKeyExchange(version tlshacks.ProtocolVersion, cipherSuites []uint16, kind parameterKind) ([]Parameter, error) {
+	var suites []uint16
+	for _, code := range cipherSuites {
+		suite := tlshacks.MakeCipherSuite(code)
+		if isSignedKeyExchange(suite) && (kind == signatureAlgorithmParameter || strings.Contains(suite.Name, "_ECDHE_")) {
+			suites = append(suites, code)
+		}
+	}
+	if len(suites) == 0 {
+		return nil, nil
+	}
+
+	remaining := slices.Clone(kind.candidates())
+	var found []uint16
+	for len(remaining) > 0 {
+		groups, signatureAlgorithms := remaining, signatureAlgorithmCandidates
+		if kind == signatureAlgorithmParameter {
+			groups, signatureAlgorithms = groupCandidates, remaining
+		}
+		result, err := s.probe(s.builder(version, suites, groups, signatureAlgorithms))
+		if err == errRejected {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if _, ok := negotiated(result, version); !ok {
+			break
+		}
+		var ske *serverKeyExchange
+		for _, message := range result.Messages {
+			if message.Type == 12 {
+				ske = parseServerKeyExchange(version, result.ServerHello.CipherSuite, message.Body())
+			}
+		}
+		if ske == nil {
+			break
+		}
+		value := ske.group
+		if kind == signatureAlgorithmParameter {
+			value = ske.signatureAlgorithm
+		}
+		if value == nil || !slices.Contains(remaining, *value) {
+			break
+		}
+		found = append(found, *value)
+		remaining = slices.DeleteFunc(remaining, func(v uint16) bool { return v == *value })
+	}
+	return kind.parameters(found), nil
+}
+
+func (s *scanner) scanHelloRetryRequest(report *Report) error {
+	builder := s.builder(0x0304, report.tls13CipherSuites(), groupCandidates, signatureAlgorithmCandidates)
+	setExtension(builder, tlshacks.KeyShareExtension())
+	result, err := s.probe(builder)
+	if err == errRejected {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if result.ServerHello.Info.HelloRetryRequest {
+		report.HelloRetryRequest = true
+		for _, ext := range result.ServerHello.Extensions {
+			if ext.Type == 44 {
+				report.HelloRetryRequestCookie = true
+			}
+		}
+	}
+	return nil
+}
+
+// greaseECHExtension returns an outer encrypted_client_hello extension with a random
+// config ID, encapsulated key, and payload, as described in draft-ietf-tls-esni, Section 6.2
+func greaseECHExtension() tlshacks.BuilderExtension {
+	random := func(n int) []byte {
+		b := make([]byte, n)
+		rand.Read(b)
+		return b
+	}
+	b := cryptobyte.NewBuilder(nil)
+	b.AddUint8(0)  // outer
+	b.AddUint16(1) // HKDF-SHA256
+	b.AddUint16(1) // AES-128-GCM
+	b.AddBytes(random(1))
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes(random(32)) })
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes(random(144)) })
+	return tlshacks.BuilderExtension{Type: 0xfe0d, Data: b.BytesOrPanic()}
+}
+
+// bogusECHConfigList returns an ECHConfigList containing a single config with a random
+// public key, which the server won't be able to decrypt
+func bogusECHConfigList(publicName string) []byte {
+	publicKey := make([]byte, 32)
+	rand.Read(publicKey)
+	b := cryptobyte.NewBuilder(nil)
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+		b.AddUint16(0xfe0d)
+		b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+			b.AddUint8(publicKey[0]) // config_id
+			b.AddUint16(0x0020)      // DHKEM(X25519, HKDF-SHA256)
+			b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes(publicKey) })
+			b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+				b.AddUint16(1) // HKDF-SHA256
+				b.AddUint16(1) // AES-128-GCM
+			})
+			b.AddUint8(0) // maximum_name_length
+			b.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes([]byte(publicName)) })
+			b.AddUint16(0) // extensions
+		})
+	})
+	return b.BytesOrPanic()
+}
+
+// scanECH checks whether the server tolerates a GREASE ECH extension, and, by offering
+// an ECH config which it can't possibly have, whether it sends retry configs
+func (s *scanner) scanECH(report *Report) error {
+	builder := s.builder(0x0304, report.tls13CipherSuites(), groupCandidates, signatureAlgorithmCandidates)
+	builder.Extensions = append(builder.Extensions, greaseECHExtension())
+	_, err := s.probe(builder)
+	if err != nil && err != errRejected {
+		return err
+	}
+	report.ECH.GreaseAccepted = err == nil
+
+	publicName := s.serverName
+	if publicName == "" {
+		publicName = "example.com"
+	}
+	conn, handshakeErr, err := s.handshake(&tls.Config{
+		MinVersion:                     tls.VersionTLS13,
+		EncryptedClientHelloConfigList: bogusECHConfigList(publicName),
+		EncryptedClientHelloRejectionVerify: func(tls.ConnectionState) error {
+			return nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+	conn.Close()
+	var rejection *tls.ECHRejectionError
+	if errors.As(handshakeErr, &rejection) {
+		report.ECH.RetryConfigs = rejection.RetryConfigList
+	}
+	return nil
+}
+
+// handshake connects to the server and performs a handshake using crypto/tls.  The
+// last return value is non-nil only if connecting failed; the result of the handshake
+// is returned separately.  conn must be closed by the caller.
+func (s *scanner) handshake(config *tls.Config) (conn *tls.Conn, handshakeErr error, err error) {
+	rawConn, err := s.dial()
+	if err != nil {
+		return nil, nil, err
+	}
+	config = config.Clone()
+	config.ServerName = s.serverName
+	config.InsecureSkipVerify = true
+	if config.MinVersion == 0 {
+		config.MinVersion = tls.VersionTLS10
+	}
+	conn = tls.Client(rawConn, config)
+	return conn, conn.HandshakeContext(s.ctx), nil
+}
+
+// scanProtocols offers each ALPN protocol on its own using crypto/tls, since in
+// TLS 1.3 the server's selection is encrypted
+func (s *scanner) scanProtocols(report *Report, protocols []string) error {
+	for _, protocol := range protocols {
+		conn, handshakeErr, err := s.handshake(&tls.Config{NextProtos: []string{protocol}})
+		if err != nil {
+			return err
+		}
+		if handshakeErr == nil && conn.ConnectionState().NegotiatedProtocol == protocol {
+			report.Protocols = append(report.Protocols, protocol)
+		}
+		conn.Close()
+	}
+	return nil
+}
+
+// resumes connects to the server twice using crypto/tls, and reports whether
+// the second connection resumed the first
+func (s *scanner) resumes(version uint16) (bool, error) {
+	config := &tls.Config{
+		MinVersion:         version,
+		MaxVersion:         version,
+		ClientSessionCache: tls.NewLRUClientSessionCache(1),
+	}
+	conn, handshakeErr, err := s.handshake(config)
+	if err != nil {
+		return false, err
+	}
+	if handshakeErr == nil && version == tls.VersionTLS13 {
+		// TLS 1.3 tickets are sent after the handshake, so read
+		// for a little while to give crypto/tls a chance to process them
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		conn.Read(make([]byte, 1))
+	}
+	conn.Close()
+	if handshakeErr != nil {
+		return false, nil
+	}
+
+	conn, handshakeErr, err = s.handshake(config)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	return handshakeErr == nil && conn.ConnectionState().DidResume, nil
+}
+
+func (s *scanner) scanResumption(report *Report) error {
+	var err error
+	if report.supports(0x0303) {
+		if report.Resumption.TLS12SessionTicket, err = s.resumes(tls.VersionTLS12); err != nil {
+			return err
+		}
+	}
+	if report.supports(0x0304) {
+		if report.Resumption.TLS13PSK, err = s.resumes(tls.VersionTLS13); err != nil {
+			return err
+		}
+	}
+	return nil
+}
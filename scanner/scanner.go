@@ -0,0 +1,356 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+// Package scanner enumerates the TLS capabilities of a server by sending it crafted
+// ClientHellos and examining its responses.
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"time"
+
+	"src.agwa.name/tlshacks"
+)
+
+type Config struct {
+	// ServerName is sent in the server_name extension.  If empty, the host
+	// portion of the address is used, unless it is an IP address.
+	ServerName string
+
+	// Timeout limits each connection to the server.  Defaults to 10 seconds.
+	Timeout time.Duration
+
+	// Protocols are the ALPN protocols to probe for.  Defaults to DefaultProtocols.
+	Protocols []string
+}
+
+var DefaultProtocols = []string{"h2", "http/1.1", "http/1.0", "acme-tls/1", "dot"}
+
+// Versions are the protocol versions probed for, from lowest to highest
+var Versions = []tlshacks.ProtocolVersion{0x0300, 0x0301, 0x0302, 0x0303, 0x0304}
+
+type Report struct {
+	Address    string          `json:"address"`
+	ServerName string          `json:"server_name,omitempty"`
+	Versions   []VersionReport `json:"versions"`
+	Protocols  []string        `json:"protocols"`
+
+	// HelloRetryRequest is true if the server responded with a HelloRetryRequest to a
+	// TLS 1.3 ClientHello without any key shares
+	HelloRetryRequest       bool `json:"hello_retry_request"`
+	HelloRetryRequestCookie bool `json:"hello_retry_request_cookie"`
+
+	Resumption Resumption `json:"resumption"`
+	ECH        ECH        `json:"ech"`
+}
+
+type VersionReport struct {
+	Version     tlshacks.ProtocolVersion `json:"version"`
+	VersionName string                   `json:"version_name"`
+	Supported   bool                     `json:"supported"`
+
+	// CipherSuites are in the order the server selects them
+	CipherSuites []tlshacks.CipherSuite `json:"cipher_suites,omitempty"`
+
+	// ServerPreference is true if the server's selection does not depend on the
+	// order of the client's cipher suites
+	ServerPreference bool `json:"server_preference"`
+
+	// Groups are in the order the server selects them.  In TLS 1.2 and below, only
+	// ECDHE groups are detected, and only if an ECDHE cipher suite is supported.
+	Groups []Parameter `json:"groups,omitempty"`
+
+	// In TLS 1.2, SignatureAlgorithms are in the order the server selects them.  In TLS 1.3,
+	// the server's choice is encrypted, so SignatureAlgorithms contains each algorithm
+	// which the server accepted when offered on its own.
+	SignatureAlgorithms []Parameter `json:"signature_algorithms,omitempty"`
+}
+
+type Parameter struct {
+	Code uint16 `json:"code"`
+	Name string `json:"name,omitempty"`
+}
+
+func (p Parameter) String() string {
+	if p.Name == "" {
+		return fmt.Sprintf("0x%04x", p.Code)
+	}
+	return fmt.Sprintf("0x%04x %s", p.Code, p.Name)
+}
+
+type Resumption struct {
+	TLS12SessionTicket bool `json:"tls12_session_ticket"`
+	TLS13PSK           bool `json:"tls13_psk"`
+}
+
+type ECH struct {
+	// GreaseAccepted is true if the server completed a ServerHello in response
+	// to a ClientHello containing a GREASE encrypted_client_hello extension
+	GreaseAccepted bool `json:"grease_accepted"`
+
+	// RetryConfigs is the ECHConfigList sent by the server when it rejected ECH.
+	// If empty, the server doesn't support ECH.
+	RetryConfigs []byte `json:"retry_configs,omitempty"`
+}
+
+type scanner struct {
+	ctx        context.Context
+	address    string
+	serverName string
+	timeout    time.Duration
+}
+
+var errRejected = errors.New("server rejected ClientHello")
+
+// Scan probes the server at address, which is a host:port.  An error is returned only
+// if the server couldn't be contacted; a server that rejects a probe is reported as
+// not supporting whatever was probed.
+func Scan(ctx context.Context, address string, config *Config) (*Report, error) {
+	if config == nil {
+		config = new(Config)
+	}
+	s := &scanner{
+		ctx:        ctx,
+		address:    address,
+		serverName: config.ServerName,
+		timeout:    config.Timeout,
+	}
+	if s.serverName == "" {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		if net.ParseIP(host) == nil {
+			s.serverName = host
+		}
+	}
+	if s.timeout == 0 {
+		s.timeout = 10 * time.Second
+	}
+	protocols := config.Protocols
+	if protocols == nil {
+		protocols = DefaultProtocols
+	}
+
+	report := &Report{
+		Address:    address,
+		ServerName: s.serverName,
+		Protocols:  []string{},
+	}
+	for _, version := range Versions {
+		versionReport, err := s.scanVersion(version)
+		if err != nil {
+			return nil, err
+		}
+		report.Versions = append(report.Versions, *versionReport)
+	}
+	if report.supports(0x0304) {
+		if err := s.scanHelloRetryRequest(report); err != nil {
+			return nil, err
+		}
+		if err := s.scanECH(report); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.scanProtocols(report, protocols); err != nil {
+		return nil, err
+	}
+	if err := s.scanResumption(report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (report *Report) supports(version tlshacks.ProtocolVersion) bool {
+	for _, v := range report.Versions {
+		if v.Version == version {
+			return v.Supported
+		}
+	}
+	return false
+}
+
+func (s *scanner) dial() (net.Conn, error) {
+	dialer := net.Dialer{Timeout: s.timeout}
+	conn, err := dialer.DialContext(s.ctx, "tcp", s.address)
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.SetDeadline(time.Now().Add(s.timeout)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// probe sends the ClientHello and returns the server's response.  errRejected is
+// returned if the server sent an alert, closed the connection, or otherwise failed
+// to send a well-formed response.
+func (s *scanner) probe(builder *tlshacks.ClientHelloBuilder) (*tlshacks.ReplayResult, error) {
+	clientHello, err := builder.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := s.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	result, err := tlshacks.Replay(conn, clientHello)
+	if err != nil || result.Alert != nil {
+		return nil, errRejected
+	}
+	return result, nil
+}
+
+// builder returns a ClientHelloBuilder for the given version which offers everything
+// that this package knows about, except as overridden by the arguments
+func (s *scanner) builder(version tlshacks.ProtocolVersion, cipherSuites []uint16, groups []uint16, signatureAlgorithms []uint16) *tlshacks.ClientHelloBuilder {
+	builder := &tlshacks.ClientHelloBuilder{
+		MinVersion:   version,
+		MaxVersion:   version,
+		CipherSuites: cipherSuites,
+	}
+	if s.serverName != "" {
+		builder.Extensions = append(builder.Extensions, tlshacks.ServerNameExtension(s.serverName))
+	}
+	builder.Extensions = append(builder.Extensions,
+		tlshacks.SupportedGroupsExtension(groups...),
+		tlshacks.ECPointFormatsExtension(0),
+	)
+	if version >= 0x0303 {
+		builder.Extensions = append(builder.Extensions, tlshacks.SignatureAlgorithmsExtension(signatureAlgorithms...))
+	}
+	builder.Extensions = append(builder.Extensions, tlshacks.RenegotiationInfoExtension())
+	if version >= 0x0304 {
+		var shares []tlshacks.KeyShareEntry
+		for _, group := range groups {
+			if share := keyShare(group); share != nil && len(shares) < 2 {
+				shares = append(shares, *share)
+			}
+		}
+		builder.Extensions = append(builder.Extensions,
+			tlshacks.PSKKeyExchangeModesExtension(1),
+			tlshacks.KeyShareExtension(shares...),
+		)
+	}
+	return builder
+}
+
+// negotiated returns the cipher suite selected by a ServerHello (or HelloRetryRequest)
+// for the given version, or false if result doesn't contain one
+func negotiated(result *tlshacks.ReplayResult, version tlshacks.ProtocolVersion) (uint16, bool) {
+	if result.ServerHello == nil || result.ServerHello.Info.Version != version {
+		return 0, false
+	}
+	return result.ServerHello.CipherSuite.CodeUint16(), true
+}
+
+// cipherSuiteCandidates returns every known cipher suite usable with the given version
+func cipherSuiteCandidates(version tlshacks.ProtocolVersion) []uint16 {
+	var suites []uint16
+	for code, suite := range tlshacks.CipherSuites {
+		if suite.Grease || suite.Name == "" || code == 0x00ff || code == 0x5600 {
+			continue
+		}
+		if isTLS13Suite(code) == (version >= 0x0304) {
+			suites = append(suites, code)
+		}
+	}
+	slices.Sort(suites)
+	return suites
+}
+
+func isTLS13Suite(code uint16) bool {
+	return code>>8 == 0x13
+}
+
+func (s *scanner) scanVersion(version tlshacks.ProtocolVersion) (*VersionReport, error) {
+	report := &VersionReport{
+		Version:     version,
+		VersionName: version.String(),
+	}
+
+	remaining := cipherSuiteCandidates(version)
+	var found []uint16
+	for len(remaining) > 0 {
+		result, err := s.probe(s.builder(version, remaining, groupCandidates, signatureAlgorithmCandidates))
+		if err == errRejected {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		suite, ok := negotiated(result, version)
+		if !ok || !slices.Contains(remaining, suite) {
+			break
+		}
+		found = append(found, suite)
+		remaining = slices.DeleteFunc(remaining, func(s uint16) bool { return s == suite })
+	}
+	if len(found) == 0 {
+		return report, nil
+	}
+	report.Supported = true
+	for _, suite := range found {
+		report.CipherSuites = append(report.CipherSuites, tlshacks.MakeCipherSuite(suite))
+	}
+
+	if len(found) > 1 {
+		reversed := slices.Clone(found)
+		slices.Reverse(reversed)
+		result, err := s.probe(s.builder(version, reversed, groupCandidates, signatureAlgorithmCandidates))
+		if err != nil && err != errRejected {
+			return nil, err
+		}
+		if err == nil {
+			suite, _ := negotiated(result, version)
+			report.ServerPreference = suite == found[0]
+		}
+	}
+
+	var err error
+	if version >= 0x0304 {
+		if report.Groups, err = s.scanTLS13Groups(found); err != nil {
+			return nil, err
+		}
+		if report.SignatureAlgorithms, err = s.scanTLS13SignatureAlgorithms(found, report.Groups); err != nil {
+			return nil, err
+		}
+	} else {
+		if report.Groups, err = s.scanServerKeyExchange(version, found, groupParameter); err != nil {
+			return nil, err
+		}
+		if version >= 0x0303 {
+			if report.SignatureAlgorithms, err = s.scanServerKeyExchange(version, found, signatureAlgorithmParameter); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return report, nil
+}
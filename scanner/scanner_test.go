@@ -0,0 +1,133 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package scanner
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"src.agwa.name/tlshacks"
+)
+
+func parameterCodes(parameters []Parameter) []uint16 {
+	var codes []uint16
+	for _, p := range parameters {
+		codes = append(codes, p.Code)
+	}
+	return codes
+}
+
+func cipherSuiteCodes(suites []tlshacks.CipherSuite) []uint16 {
+	var codes []uint16
+	for _, suite := range suites {
+		codes = append(codes, suite.CodeUint16())
+	}
+	return codes
+}
+
+func TestScan(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.NotFoundHandler())
+	server.TLS = &tls.Config{
+		MinVersion:       tls.VersionTLS12,
+		CipherSuites:     []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256},
+		CurvePreferences: []tls.CurveID{tls.X25519, tls.CurveP256},
+	}
+	server.Config.ErrorLog = log.New(io.Discard, "", 0) // most probes are rejected or abandoned
+	server.StartTLS()
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	report, err := Scan(ctx, server.Listener.Addr().String(), &Config{Timeout: 10 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[tlshacks.ProtocolVersion][]uint16{
+		0x0303: {0xc02f, 0xcca8},
+		0x0304: {0x1301, 0x1302, 0x1303},
+	}
+	if len(report.Versions) != len(Versions) {
+		t.Fatalf("report has %d versions, expected %d", len(report.Versions), len(Versions))
+	}
+	for _, v := range report.Versions {
+		suites, supported := expected[v.Version]
+		if v.Supported != supported {
+			t.Errorf("%s: supported = %t, expected %t", v.VersionName, v.Supported, supported)
+		}
+		if !supported {
+			continue
+		}
+		// Which AES-GCM and ChaCha20 suites crypto/tls prefers depends on the hardware
+		codes := cipherSuiteCodes(v.CipherSuites)
+		slices.Sort(codes)
+		if !slices.Equal(codes, suites) {
+			t.Errorf("%s: cipher suites are %04x, expected %04x", v.VersionName, codes, suites)
+		}
+		if groups := parameterCodes(v.Groups); !slices.Equal(groups, []uint16{29, 23}) {
+			t.Errorf("%s: groups are %v, expected x25519 then secp256r1", v.VersionName, groups)
+		}
+		algorithms := parameterCodes(v.SignatureAlgorithms)
+		if !slices.Contains(algorithms, 0x0804) {
+			t.Errorf("%s: signature algorithms %04x don't include rsa_pss_rsae_sha256", v.VersionName, algorithms)
+		}
+		if slices.Contains(algorithms, 0x0403) {
+			t.Errorf("%s: signature algorithms %04x include ecdsa_secp256r1_sha256, but the certificate is RSA", v.VersionName, algorithms)
+		}
+		if v.Version == 0x0304 && slices.Contains(algorithms, 0x0401) {
+			t.Errorf("%s: signature algorithms %04x include rsa_pkcs1_sha256, which TLS 1.3 forbids", v.VersionName, algorithms)
+		}
+	}
+	if !slices.Equal(report.Protocols, []string{"http/1.1"}) {
+		t.Errorf("protocols are %v, expected only http/1.1", report.Protocols)
+	}
+	if !report.HelloRetryRequest || report.HelloRetryRequestCookie {
+		t.Errorf("HelloRetryRequest = %t and HelloRetryRequestCookie = %t, expected a HelloRetryRequest without a cookie", report.HelloRetryRequest, report.HelloRetryRequestCookie)
+	}
+	if !report.Resumption.TLS12SessionTicket || !report.Resumption.TLS13PSK {
+		t.Errorf("resumption is %+v, expected both kinds", report.Resumption)
+	}
+	if !report.ECH.GreaseAccepted || len(report.ECH.RetryConfigs) != 0 {
+		t.Errorf("ECH is %+v, expected GREASE to be accepted without retry configs", report.ECH)
+	}
+}
+
+func TestScanUnreachable(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	address := server.Listener.Addr().String()
+	server.Close()
+
+	if _, err := Scan(context.Background(), address, &Config{Timeout: 10 * time.Second}); err == nil {
+		t.Error("Scan of a closed port succeeded")
+	}
+}
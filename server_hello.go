@@ -0,0 +1,198 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package tlshacks
+
+import (
+	"bytes"
+
+	"golang.org/x/crypto/cryptobyte"
+)
+
+// helloRetryRequestRandom is the special value of ServerHello.random that indicates a HelloRetryRequest (RFC 8446, Section 4.1.3)
+var helloRetryRequestRandom = []byte{
+	0xCF, 0x21, 0xAD, 0x74, 0xE5, 0x9A, 0x61, 0x11, 0xBE, 0x1D, 0x8C, 0x02, 0x1E, 0x65, 0xB8, 0x91,
+	0xC2, 0xA2, 0x11, 0x16, 0x7A, 0xBB, 0x8C, 0x5E, 0x07, 0x9E, 0x09, 0xE2, 0xC8, 0xA8, 0x33, 0x9C,
+}
+
+// selected_version - RFC 8446, Section 4.2.1
+type SelectedVersionData struct {
+	Raw     []byte          `json:"raw"`
+	Valid   bool            `json:"valid"`
+	Version ProtocolVersion `json:"version"`
+}
+
+func ParseSelectedVersionData(rawData []byte) ExtensionData {
+	parsedData := &SelectedVersionData{Raw: rawData}
+	data := cryptobyte.String(rawData)
+	if !data.ReadUint16((*uint16)(&parsedData.Version)) || !data.Empty() {
+		return parsedData
+	}
+	parsedData.Valid = true
+	return parsedData
+}
+
+// key_share in a ServerHello or HelloRetryRequest - RFC 8446, Section 4.2.8
+type ServerKeyShareData struct {
+	Raw         []byte `json:"raw"`
+	Valid       bool   `json:"valid"`
+	Group       uint16 `json:"group"`
+	KeyExchange []byte `json:"key_exchange,omitempty"` // absent in a HelloRetryRequest
+}
+
+func ParseServerKeyShareData(rawData []byte) ExtensionData {
+	parsedData := &ServerKeyShareData{Raw: rawData}
+	data := cryptobyte.String(rawData)
+	if !data.ReadUint16(&parsedData.Group) {
+		return parsedData
+	}
+	if !data.Empty() && (!data.ReadUint16LengthPrefixed((*cryptobyte.String)(&parsedData.KeyExchange)) || len(parsedData.KeyExchange) == 0) {
+		return parsedData
+	}
+	if !data.Empty() {
+		return parsedData
+	}
+	parsedData.Valid = true
+	return parsedData
+}
+
+var serverExtensionParsers = map[uint16]ExtensionParser{
+	0:  ParseEmptyExtensionData,
+	11: ParseECPointFormatsData,
+	16: ParseALPNData,
+	22: ParseEmptyExtensionData,
+	23: ParseEmptyExtensionData,
+	43: ParseSelectedVersionData,
+	51: ParseServerKeyShareData,
+}
+
+type ServerHelloInfo struct {
+	Raw []byte `json:"raw"`
+
+	Version           ProtocolVersion   `json:"version"`
+	Random            []byte            `json:"random"`
+	SessionID         []byte            `json:"session_id"`
+	CipherSuite       CipherSuite       `json:"cipher_suite"`
+	CompressionMethod CompressionMethod `json:"compression_method"`
+	Extensions        []Extension       `json:"extensions"`
+
+	Info struct {
+		HelloRetryRequest bool            `json:"hello_retry_request"`
+		Version           ProtocolVersion `json:"version"` // the negotiated version, taking supported_versions into account
+		VersionName       string          `json:"version_name"`
+		Group             *uint16         `json:"group"` // from key_share
+		Protocol          *string         `json:"protocol"`
+	} `json:"info"`
+}
+
+func UnmarshalServerHello(handshakeBytes []byte) *ServerHelloInfo {
+	info := &ServerHelloInfo{Raw: handshakeBytes}
+	handshakeMessage := cryptobyte.String(handshakeBytes)
+
+	var messageType uint8
+	if !handshakeMessage.ReadUint8(&messageType) || messageType != 2 {
+		return nil
+	}
+
+	var serverHello cryptobyte.String
+	if !handshakeMessage.ReadUint24LengthPrefixed(&serverHello) || !handshakeMessage.Empty() {
+		return nil
+	}
+
+	if !serverHello.ReadUint16((*uint16)(&info.Version)) {
+		return nil
+	}
+
+	if !serverHello.ReadBytes(&info.Random, 32) {
+		return nil
+	}
+
+	if !serverHello.ReadUint8LengthPrefixed((*cryptobyte.String)(&info.SessionID)) {
+		return nil
+	}
+
+	var cipherSuite uint16
+	if !serverHello.ReadUint16(&cipherSuite) {
+		return nil
+	}
+	info.CipherSuite = MakeCipherSuite(cipherSuite)
+
+	if !serverHello.ReadUint8((*uint8)(&info.CompressionMethod)) {
+		return nil
+	}
+
+	info.Info.HelloRetryRequest = bytes.Equal(info.Random, helloRetryRequestRandom)
+	info.Info.Version = info.Version
+	info.Extensions = []Extension{}
+
+	if !serverHello.Empty() {
+		var extensions cryptobyte.String
+		if !serverHello.ReadUint16LengthPrefixed(&extensions) {
+			return nil
+		}
+		for !extensions.Empty() {
+			var extType uint16
+			var extData cryptobyte.String
+			if !extensions.ReadUint16(&extType) || !extensions.ReadUint16LengthPrefixed(&extData) {
+				return nil
+			}
+
+			parseData := serverExtensionParsers[extType]
+			if parseData == nil {
+				parseData = ParseUnknownExtensionData
+			}
+			data := parseData(extData)
+
+			info.Extensions = append(info.Extensions, Extension{
+				Type:    extType,
+				Name:    ExtensionName(extType),
+				Grease:  Extensions[extType].Grease,
+				Private: Extensions[extType].Private,
+				Data:    data,
+			})
+
+			switch data := data.(type) {
+			case *SelectedVersionData:
+				if data.Valid {
+					info.Info.Version = data.Version
+				}
+			case *ServerKeyShareData:
+				if data.Valid {
+					info.Info.Group = &data.Group
+				}
+			case *ALPNData:
+				if data.Valid && len(data.Protocols) == 1 {
+					info.Info.Protocol = &data.Protocols[0]
+				}
+			}
+		}
+		if !serverHello.Empty() {
+			return nil
+		}
+	}
+
+	info.Info.VersionName = info.Info.Version.String()
+	return info
+}
@@ -0,0 +1,310 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package tlshacks
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// GreasePlaceholder stands in for GREASE values in a ClientHelloSpec, because
+// clients are expected to choose a fresh random GREASE value for each connection
+const GreasePlaceholder = 0x0a0a
+
+// SpecExtension describes one extension in a ClientHelloSpec.  Extensions
+// which have a structured representation populate the corresponding field;
+// all other extensions are replayed verbatim from Data.
+type SpecExtension struct {
+	Type   uint16 `json:"type"`
+	Name   string `json:"name,omitempty"`
+	Grease bool   `json:"grease,omitempty"`
+
+	ServerName            bool     `json:"server_name,omitempty"` // the server name is provided at connection time
+	Groups                []uint16 `json:"groups,omitempty"`
+	PointFormats          []uint16 `json:"point_formats,omitempty"`
+	SignatureAlgorithms   []uint16 `json:"signature_algorithms,omitempty"`
+	Protocols             []string `json:"protocols,omitempty"` // ALPN, or ALPS for application_settings
+	CompressionAlgorithms []uint16 `json:"compression_algorithms,omitempty"`
+	RecordSizeLimit       uint16   `json:"record_size_limit,omitempty"`
+	Versions              []uint16 `json:"versions,omitempty"`
+	KeyShareGroups        []uint16 `json:"key_share_groups,omitempty"`
+	PaddingLength         int      `json:"padding_length,omitempty"` // the length observed in the captured ClientHello
+	Data                  []byte   `json:"data,omitempty"`
+}
+
+// ClientHelloSpec describes a ClientHello in enough detail for a uTLS-style
+// ClientHelloSpec to reproduce it.  GREASE values are replaced with GreasePlaceholder.
+type ClientHelloSpec struct {
+	TLSVersionMin      uint16          `json:"tls_version_min"`
+	TLSVersionMax      uint16          `json:"tls_version_max"`
+	CipherSuites       []uint16        `json:"cipher_suites"`
+	CompressionMethods []uint8         `json:"compression_methods"`
+	SessionIDLength    int             `json:"session_id_length"`
+	Extensions         []SpecExtension `json:"extensions"`
+}
+
+func greaseToPlaceholder(v uint16) uint16 {
+	if isGreaseValue(v) {
+		return GreasePlaceholder
+	}
+	return v
+}
+
+func placeholderList[T ~uint16](list []T) []uint16 {
+	result := make([]uint16, len(list))
+	for i, v := range list {
+		result[i] = greaseToPlaceholder(uint16(v))
+	}
+	return result
+}
+
+func makeSpecExtension(ext *Extension) SpecExtension {
+	spec := SpecExtension{Type: ext.Type, Name: ext.Name, Grease: ext.Grease}
+	if ext.Grease {
+		spec.Type = GreasePlaceholder
+		spec.Data = extensionRaw(ext.Data)
+		return spec
+	}
+	if !extensionValid(ext.Data) {
+		spec.Data = extensionRaw(ext.Data)
+		return spec
+	}
+	switch data := ext.Data.(type) {
+	case *ServerNameData:
+		spec.ServerName = true
+	case *SupportedGroupsData:
+		spec.Groups = placeholderList(data.Groups)
+	case *ECPointFormatsData:
+		spec.PointFormats = data.Formats
+	case *SignatureAlgorithmsData:
+		spec.SignatureAlgorithms = placeholderList(data.Algorithms)
+	case *ALPNData:
+		spec.Protocols = data.Protocols
+	case *ApplicationSettingsData:
+		spec.Protocols = data.Protocols
+	case *CompressCertificateData:
+		for _, algorithm := range data.Algorithms {
+			spec.CompressionAlgorithms = append(spec.CompressionAlgorithms, algorithm.Code)
+		}
+	case *RecordSizeLimitData:
+		spec.RecordSizeLimit = data.Limit
+	case *SupportedVersionsData:
+		spec.Versions = placeholderList(data.Versions)
+	case *KeyShareData:
+		for _, share := range data.Shares {
+			spec.KeyShareGroups = append(spec.KeyShareGroups, greaseToPlaceholder(share.Group))
+		}
+	default:
+		if ext.Type == 21 {
+			spec.PaddingLength = len(extensionRaw(ext.Data))
+		} else {
+			spec.Data = extensionRaw(ext.Data)
+		}
+	}
+	return spec
+}
+
+// MakeClientHelloSpec converts hello into a ClientHelloSpec
+func MakeClientHelloSpec(hello *ClientHelloInfo) *ClientHelloSpec {
+	spec := &ClientHelloSpec{
+		TLSVersionMin:      uint16(hello.Version),
+		TLSVersionMax:      uint16(hello.Version),
+		CipherSuites:       make([]uint16, len(hello.CipherSuites)),
+		CompressionMethods: make([]uint8, len(hello.CompressionMethods)),
+		SessionIDLength:    len(hello.SessionID),
+		Extensions:         make([]SpecExtension, len(hello.Extensions)),
+	}
+	if versions := supportedVersionsOf(hello); len(versions) > 0 {
+		spec.TLSVersionMin, spec.TLSVersionMax = uint16(versions[0]), uint16(versions[0])
+		for _, v := range versions {
+			spec.TLSVersionMin = min(spec.TLSVersionMin, uint16(v))
+			spec.TLSVersionMax = max(spec.TLSVersionMax, uint16(v))
+		}
+	}
+	for i, suite := range hello.CipherSuites {
+		spec.CipherSuites[i] = greaseToPlaceholder(suite.CodeUint16())
+	}
+	for i, method := range hello.CompressionMethods {
+		spec.CompressionMethods[i] = uint8(method)
+	}
+	for i := range hello.Extensions {
+		spec.Extensions[i] = makeSpecExtension(&hello.Extensions[i])
+	}
+	return spec
+}
+
+func goVersion(v uint16) string {
+	switch v {
+	case 0x0300:
+		return "tls.VersionSSL30"
+	case 0x0301:
+		return "tls.VersionTLS10"
+	case 0x0302:
+		return "tls.VersionTLS11"
+	case 0x0303:
+		return "tls.VersionTLS12"
+	case 0x0304:
+		return "tls.VersionTLS13"
+	case GreasePlaceholder:
+		return "tls.GREASE_PLACEHOLDER"
+	default:
+		return fmt.Sprintf("0x%04x", v)
+	}
+}
+
+func goUint16s(list []uint16, typeName string, format func(uint16) string) string {
+	elems := make([]string, len(list))
+	for i, v := range list {
+		if v == GreasePlaceholder {
+			elems[i] = typeName + "(tls.GREASE_PLACEHOLDER)"
+		} else {
+			elems[i] = format(v)
+		}
+	}
+	return strings.Join(elems, ", ")
+}
+
+func certCompressionAlgo(v uint16) string {
+	switch v {
+	case 1:
+		return "tls.CertCompressionZlib"
+	case 2:
+		return "tls.CertCompressionBrotli"
+	case 3:
+		return "tls.CertCompressionZstd"
+	}
+	return "tls.CertCompressionAlgo(" + goHex(v) + ")"
+}
+
+func goHex(v uint16) string { return fmt.Sprintf("0x%04x", v) }
+
+func goBytes(data []byte) string {
+	elems := make([]string, len(data))
+	for i, b := range data {
+		elems[i] = fmt.Sprintf("0x%02x", b)
+	}
+	return "[]byte{" + strings.Join(elems, ", ") + "}"
+}
+
+func (ext *SpecExtension) goSource() string {
+	switch {
+	case ext.Grease:
+		return "&tls.UtlsGREASEExtension{}"
+	case ext.ServerName:
+		return "&tls.SNIExtension{}"
+	case ext.Groups != nil:
+		return "&tls.SupportedCurvesExtension{Curves: []tls.CurveID{" + goUint16s(ext.Groups, "tls.CurveID", func(v uint16) string { return "tls.CurveID(" + goHex(v) + ")" }) + "}}"
+	case ext.PointFormats != nil:
+		formats := make([]byte, len(ext.PointFormats))
+		for i, f := range ext.PointFormats {
+			formats[i] = byte(f)
+		}
+		return "&tls.SupportedPointsExtension{SupportedPoints: " + goBytes(formats) + "}"
+	case ext.SignatureAlgorithms != nil && ext.Type == 50:
+		return "&tls.SignatureAlgorithmsCertExtension{SupportedSignatureAlgorithms: []tls.SignatureScheme{" + goUint16s(ext.SignatureAlgorithms, "tls.SignatureScheme", goHex) + "}}"
+	case ext.SignatureAlgorithms != nil && ext.Type == 34:
+		return "&tls.DelegatedCredentialsExtension{SupportedSignatureAlgorithms: []tls.SignatureScheme{" + goUint16s(ext.SignatureAlgorithms, "tls.SignatureScheme", goHex) + "}}"
+	case ext.SignatureAlgorithms != nil:
+		return "&tls.SignatureAlgorithmsExtension{SupportedSignatureAlgorithms: []tls.SignatureScheme{" + goUint16s(ext.SignatureAlgorithms, "tls.SignatureScheme", goHex) + "}}"
+	case ext.Protocols != nil && ext.Type == 17513:
+		return fmt.Sprintf("&tls.ApplicationSettingsExtension{SupportedProtocols: %#v}", ext.Protocols)
+	case ext.Protocols != nil && ext.Type == 17613:
+		return fmt.Sprintf("&tls.ApplicationSettingsExtensionNew{SupportedProtocols: %#v}", ext.Protocols)
+	case ext.Protocols != nil:
+		return fmt.Sprintf("&tls.ALPNExtension{AlpnProtocols: %#v}", ext.Protocols)
+	case ext.CompressionAlgorithms != nil:
+		return "&tls.UtlsCompressCertExtension{Algorithms: []tls.CertCompressionAlgo{" + goUint16s(ext.CompressionAlgorithms, "tls.CertCompressionAlgo", certCompressionAlgo) + "}}"
+	case ext.RecordSizeLimit != 0:
+		return fmt.Sprintf("&tls.FakeRecordSizeLimitExtension{Limit: 0x%04x}", ext.RecordSizeLimit)
+	case ext.Versions != nil:
+		return "&tls.SupportedVersionsExtension{Versions: []uint16{" + goUint16s(ext.Versions, "uint16", goVersion) + "}}"
+	case ext.Type == 51 && ext.Data == nil:
+		shares := make([]string, len(ext.KeyShareGroups))
+		for i, group := range ext.KeyShareGroups {
+			if group == GreasePlaceholder {
+				shares[i] = "{Group: tls.CurveID(tls.GREASE_PLACEHOLDER), Data: []byte{0}}"
+			} else {
+				shares[i] = "{Group: tls.CurveID(" + goHex(group) + ")}"
+			}
+		}
+		return "&tls.KeyShareExtension{KeyShares: []tls.KeyShare{" + strings.Join(shares, ", ") + "}}"
+	case ext.Type == 21 && ext.Data == nil:
+		// Invalid (non-zero) padding has Data set and is replayed verbatim below
+		return "&tls.UtlsPaddingExtension{GetPaddingLen: tls.BoringPaddingStyle}"
+	case ext.Type == 5 && bytes.Equal(ext.Data, []byte{1, 0, 0, 0, 0}):
+		return "&tls.StatusRequestExtension{}"
+	case ext.Type == 18 && len(ext.Data) == 0:
+		return "&tls.SCTExtension{}"
+	case ext.Type == 23 && len(ext.Data) == 0:
+		return "&tls.ExtendedMasterSecretExtension{}"
+	case ext.Type == 35 && len(ext.Data) == 0:
+		return "&tls.SessionTicketExtension{}"
+	case ext.Type == 0xff01 && len(ext.Data) == 1 && ext.Data[0] == 0:
+		return "&tls.RenegotiationInfoExtension{Renegotiation: tls.RenegotiateOnceAsClient}"
+	default:
+		return fmt.Sprintf("&tls.GenericExtension{Id: %s, Data: %s}", goHex(ext.Type), goBytes(ext.Data))
+	}
+}
+
+// goComment returns a comment to follow the extension's Go source, or the empty string
+func (ext *SpecExtension) goComment() string {
+	if ext.Type == 21 && !ext.Grease && ext.Data == nil {
+		return fmt.Sprintf("%d bytes in the captured ClientHello", ext.PaddingLength)
+	}
+	return ""
+}
+
+// GoSource returns a Go expression which constructs the equivalent uTLS ClientHelloSpec,
+// assuming the uTLS package is imported as "tls"
+func (spec *ClientHelloSpec) GoSource() string {
+	b := new(strings.Builder)
+	fmt.Fprintf(b, "tls.ClientHelloSpec{\n")
+	fmt.Fprintf(b, "\tTLSVersMin: %s,\n", goVersion(spec.TLSVersionMin))
+	fmt.Fprintf(b, "\tTLSVersMax: %s,\n", goVersion(spec.TLSVersionMax))
+	fmt.Fprintf(b, "\tCipherSuites: []uint16{\n")
+	for _, suite := range spec.CipherSuites {
+		if suite == GreasePlaceholder {
+			fmt.Fprintf(b, "\t\ttls.GREASE_PLACEHOLDER,\n")
+		} else {
+			fmt.Fprintf(b, "\t\t0x%04x, // %s\n", suite, CipherSuites[suite].Name)
+		}
+	}
+	fmt.Fprintf(b, "\t},\n")
+	fmt.Fprintf(b, "\tCompressionMethods: %s,\n", goBytes(spec.CompressionMethods))
+	fmt.Fprintf(b, "\tExtensions: []tls.TLSExtension{\n")
+	for i := range spec.Extensions {
+		ext := &spec.Extensions[i]
+		if comment := ext.goComment(); comment != "" {
+			fmt.Fprintf(b, "\t\t%s, // %s\n", ext.goSource(), comment)
+		} else {
+			fmt.Fprintf(b, "\t\t%s,\n", ext.goSource())
+		}
+	}
+	fmt.Fprintf(b, "\t},\n")
+	fmt.Fprintf(b, "}\n")
+	return b.String()
+}
@@ -0,0 +1,114 @@
+// Copyright (C) 2026 Andrew Ayer
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+//
+// Except as contained in this notice, the name(s) of the above copyright
+// holders shall not be used in advertising or otherwise to promote the
+// sale, use or other dealings in this Software without prior written
+// authorization.
+
+package tlshacks
+
+import (
+	"go/parser"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMakeClientHelloSpec(t *testing.T) {
+	spec := MakeClientHelloSpec(readTestHello(t, "chrome-133.hex"))
+	if spec.TLSVersionMin != 0x0303 || spec.TLSVersionMax != 0x0304 {
+		t.Errorf("versions are %04x-%04x, expected 0303-0304", spec.TLSVersionMin, spec.TLSVersionMax)
+	}
+	if spec.CipherSuites[0] != GreasePlaceholder || slices.Contains(spec.CipherSuites[1:], GreasePlaceholder) {
+		t.Errorf("cipher suites are %04x, expected only the first to be GREASE", spec.CipherSuites)
+	}
+
+	var greasePositions []int
+	for i, ext := range spec.Extensions {
+		if ext.Grease {
+			greasePositions = append(greasePositions, i)
+			if ext.Type != GreasePlaceholder {
+				t.Errorf("GREASE extension %d has type %04x", i, ext.Type)
+			}
+		}
+	}
+	if expected := []int{0, len(spec.Extensions) - 1}; !slices.Equal(greasePositions, expected) {
+		t.Errorf("GREASE extensions are at %v, expected %v", greasePositions, expected)
+	}
+
+	checks := []struct {
+		extType  uint16
+		get      func(*SpecExtension) []uint16
+		expected []uint16
+	}{
+		{43, func(ext *SpecExtension) []uint16 { return ext.Versions }, []uint16{GreasePlaceholder, 0x0304, 0x0303}},
+		{10, func(ext *SpecExtension) []uint16 { return ext.Groups }, []uint16{GreasePlaceholder, 4588, 29, 23, 24}},
+		{51, func(ext *SpecExtension) []uint16 { return ext.KeyShareGroups }, []uint16{GreasePlaceholder, 4588, 29}},
+	}
+	for _, check := range checks {
+		i := slices.IndexFunc(spec.Extensions, func(ext SpecExtension) bool { return ext.Type == check.extType })
+		if i == -1 {
+			t.Errorf("extension %d missing", check.extType)
+		} else if got := check.get(&spec.Extensions[i]); !slices.Equal(got, check.expected) {
+			t.Errorf("extension %d has %v, expected %v", check.extType, got, check.expected)
+		}
+	}
+
+	if _, err := parser.ParseExpr(spec.GoSource()); err != nil {
+		t.Errorf("GoSource output does not parse: %s", err)
+	}
+}
+
+func TestMakeClientHelloSpecPadding(t *testing.T) {
+	tests := []struct {
+		data          []byte
+		paddingLength int
+		source        string
+	}{
+		{make([]byte, 100), 100, "&tls.UtlsPaddingExtension{GetPaddingLen: tls.BoringPaddingStyle}, // 100 bytes in the captured ClientHello"},
+		{[]byte{}, 0, "&tls.UtlsPaddingExtension{GetPaddingLen: tls.BoringPaddingStyle}, // 0 bytes in the captured ClientHello"},
+		{[]byte{0, 1, 0}, 0, "&tls.GenericExtension{Id: 0x0015, Data: []byte{0x00, 0x01, 0x00}},\n"},
+	}
+	for _, test := range tests {
+		builder := lintBuilder()
+		builder.Extensions = append(builder.Extensions, BuilderExtension{Type: 21, Data: test.data})
+		hello, err := builder.Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		spec := MakeClientHelloSpec(hello)
+		i := slices.IndexFunc(spec.Extensions, func(ext SpecExtension) bool { return ext.Type == 21 })
+		if i == -1 {
+			t.Fatalf("padding %x: extension missing", test.data)
+		}
+		padding := spec.Extensions[i]
+		if padding.PaddingLength != test.paddingLength {
+			t.Errorf("padding %x: PaddingLength is %d, expected %d", test.data, padding.PaddingLength, test.paddingLength)
+		}
+		source := spec.GoSource()
+		if !strings.Contains(source, test.source) {
+			t.Errorf("padding %x: GoSource output does not contain %q:\n%s", test.data, test.source, source)
+		}
+		if _, err := parser.ParseExpr(source); err != nil {
+			t.Errorf("padding %x: GoSource output does not parse: %s", test.data, err)
+		}
+	}
+}